@@ -1,16 +1,39 @@
 package goresttest
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// comparisonOperators are the operators supported by assertions that compare
+// an extracted value with the expected one
+var comparisonOperators = []string{"equals", "==", "not_equals", "!=", "contains", "not_contains"}
+
+// assertionOperators lists the operators the AssertionEngine supports for
+// each assertion type
+var assertionOperators = map[string][]string{
+	"status_code":   {"equals", "==", "not_equals", "!=", "greater_than", ">", "less_than", "<"},
+	"json_path":     comparisonOperators,
+	"xpath":         comparisonOperators,
+	"css_selector":  comparisonOperators,
+	"header":        comparisonOperators,
+	"cookie":        comparisonOperators,
+	"body_contains": {"contains", "not_contains"},
+	"regex":         {"matches", "not_matches"},
+	"response_time": {"less_than", "<", "greater_than", ">", "equals", "=="},
+	"tls_version":   {"equals", "==", "not_equals", "!=", "greater_than", ">", "less_than", "<"},
+	"cert_expiry":   {"greater_than", ">", "less_than", "<"},
+}
+
 // AssertionEngine handles test assertions
 type AssertionEngine struct{}
 
@@ -20,7 +43,7 @@ func NewAssertionEngine() *AssertionEngine {
 }
 
 // RunAssertions executes all assertions for a test result
-func (ae *AssertionEngine) RunAssertions(result *TestResult, assertions []Assertion, variables map[string]string) []string {
+func (ae *AssertionEngine) RunAssertions(result *TestResult, assertions []Assertion, variables map[string]interface{}) []string {
 	var errors []string
 
 	for _, assertion := range assertions {
@@ -32,7 +55,7 @@ func (ae *AssertionEngine) RunAssertions(result *TestResult, assertions []Assert
 	return errors
 }
 
-func (ae *AssertionEngine) runSingleAssertion(result *TestResult, assertion Assertion, variables map[string]string) error {
+func (ae *AssertionEngine) runSingleAssertion(result *TestResult, assertion Assertion, variables map[string]interface{}) error {
 	interpolatedAssertion := ae.interpolateAssertion(assertion, variables)
 	switch interpolatedAssertion.Type {
 	case "status_code":
@@ -43,19 +66,25 @@ func (ae *AssertionEngine) runSingleAssertion(result *TestResult, assertion Asse
 		return ae.assertHTMLSelector(result, interpolatedAssertion)
 	case "header":
 		return ae.assertHeader(result, interpolatedAssertion)
+	case "cookie":
+		return ae.assertCookie(result, interpolatedAssertion)
 	case "body_contains":
 		return ae.assertBodyContains(result, interpolatedAssertion)
 	case "regex":
 		return ae.assertRegex(result, interpolatedAssertion)
 	case "response_time":
 		return ae.assertResponseTime(result, interpolatedAssertion)
+	case "tls_version":
+		return ae.assertTLSVersion(result, interpolatedAssertion)
+	case "cert_expiry":
+		return ae.assertCertExpiry(result, interpolatedAssertion)
 	default:
 		return fmt.Errorf("unknown assertion type: %s", interpolatedAssertion.Type)
 	}
 }
 
 func (ae *AssertionEngine) assertStatusCode(result *TestResult, assertion Assertion) error {
-	expected, ok := assertion.Expected.(int)
+	expected, ok := wholeNumber(assertion.Expected)
 	if !ok {
 		if str, ok := assertion.Expected.(string); ok {
 			var err error
@@ -98,8 +127,12 @@ func (ae *AssertionEngine) assertStatusCode(result *TestResult, assertion Assert
 }
 
 func (ae *AssertionEngine) assertJSONPath(result *TestResult, assertion Assertion) error {
+	// Numbers are decoded like extracted variables, so that 64-bit IDs are
+	// compared exactly
 	var jsonData interface{}
-	if err := json.Unmarshal([]byte(result.Response), &jsonData); err != nil {
+	decoder := json.NewDecoder(strings.NewReader(result.Response))
+	decoder.UseNumber()
+	if err := decoder.Decode(&jsonData); err != nil {
 		return fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 
@@ -166,6 +199,20 @@ func (ae *AssertionEngine) assertHeader(result *TestResult, assertion Assertion)
 	return ae.compareValues(value, assertion.Expected, operator, "header")
 }
 
+func (ae *AssertionEngine) assertCookie(result *TestResult, assertion Assertion) error {
+	cookie := responseCookie(result.Headers, assertion.Path)
+	if cookie == nil {
+		return fmt.Errorf("cookie %s not found", assertion.Path)
+	}
+
+	operator := assertion.Operator
+	if operator == "" {
+		operator = "equals"
+	}
+
+	return ae.compareValues(cookie.Value, assertion.Expected, operator, "cookie")
+}
+
 func (ae *AssertionEngine) assertBodyContains(result *TestResult, assertion Assertion) error {
 	expected, ok := assertion.Expected.(string)
 	if !ok {
@@ -226,7 +273,7 @@ func (ae *AssertionEngine) assertRegex(result *TestResult, assertion Assertion)
 }
 
 func (ae *AssertionEngine) assertResponseTime(result *TestResult, assertion Assertion) error {
-	expectedMs, ok := assertion.Expected.(int)
+	expectedMs, ok := wholeNumber(assertion.Expected)
 	if !ok {
 		if str, ok := assertion.Expected.(string); ok {
 			var err error
@@ -266,6 +313,116 @@ func (ae *AssertionEngine) assertResponseTime(result *TestResult, assertion Asse
 	return nil
 }
 
+// wholeNumber converts an expected value holding a whole number to an int.
+// Besides YAML integers this accepts numbers extracted from JSON, which are
+// float64 or json.Number.
+func wholeNumber(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+			return int(v), true
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// assertTLSVersion compares the negotiated TLS version with the expected
+// one, e.g. 1.2 or "TLS 1.3"
+func (ae *AssertionEngine) assertTLSVersion(result *TestResult, assertion Assertion) error {
+	if result.TLSVersion == "" {
+		return fmt.Errorf("TLS version assertion failed: the request did not use TLS")
+	}
+
+	expectedName := fmt.Sprintf("%v", assertion.Expected)
+	if f, ok := assertion.Expected.(float64); ok {
+		// YAML decodes an unquoted 1.0 as a float
+		expectedName = strconv.FormatFloat(f, 'f', 1, 64)
+	}
+	expected, err := parseTLSVersion(expectedName)
+	if err != nil {
+		return err
+	}
+	actual, err := parseTLSVersion(result.TLSVersion)
+	if err != nil {
+		return err
+	}
+
+	operator := assertion.Operator
+	if operator == "" {
+		operator = "equals"
+	}
+
+	var failed bool
+	switch operator {
+	case "equals", "==":
+		failed = actual != expected
+	case "not_equals", "!=":
+		failed = actual == expected
+	case "greater_than", ">":
+		failed = actual <= expected
+	case "less_than", "<":
+		failed = actual >= expected
+	default:
+		return fmt.Errorf("unsupported operator for tls_version: %s", operator)
+	}
+	if failed {
+		return fmt.Errorf("TLS version assertion failed: expected %s %s, got %s", operator, tls.VersionName(expected), result.TLSVersion)
+	}
+	return nil
+}
+
+// assertCertExpiry compares the time left until the server certificate
+// expires with a duration such as "720h" or "30d"
+func (ae *AssertionEngine) assertCertExpiry(result *TestResult, assertion Assertion) error {
+	if result.CertificateExpiry.IsZero() {
+		return fmt.Errorf("certificate expiry assertion failed: the server did not present a certificate")
+	}
+
+	text := fmt.Sprintf("%v", assertion.Expected)
+	var expected time.Duration
+	if days, found := strings.CutSuffix(text, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return fmt.Errorf("invalid certificate expiry format: %s", text)
+		}
+		expected = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		expected, err = time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("invalid certificate expiry format: %s", text)
+		}
+	}
+
+	remaining := time.Until(result.CertificateExpiry)
+	expires := result.CertificateExpiry.UTC().Format(time.RFC3339)
+
+	operator := assertion.Operator
+	if operator == "" {
+		operator = "greater_than"
+	}
+
+	switch operator {
+	case "greater_than", ">":
+		if remaining <= expected {
+			return fmt.Errorf("certificate expiry assertion failed: expected more than %s left, certificate expires at %s", text, expires)
+		}
+	case "less_than", "<":
+		if remaining >= expected {
+			return fmt.Errorf("certificate expiry assertion failed: expected less than %s left, certificate expires at %s", text, expires)
+		}
+	default:
+		return fmt.Errorf("unsupported operator for cert_expiry: %s", operator)
+	}
+	return nil
+}
+
 func (ae *AssertionEngine) getJSONPathValue(data interface{}, path string) (interface{}, error) {
 	parts := strings.Split(strings.TrimPrefix(path, "$."), ".")
 	current := data
@@ -311,28 +468,27 @@ func (ae *AssertionEngine) getJSONPathValue(data interface{}, path string) (inte
 	return current, nil
 }
 
+// compareValues compares an actual value with the expected one using their
+// native types. Objects and lists are compared as JSON, and contains checks
+// the elements of a list.
 func (ae *AssertionEngine) compareValues(actual, expected interface{}, operator, context string) error {
 	normalizedActual, normalizedExpected := ae.normalizeTypes(actual, expected)
 	switch operator {
 	case "equals", "==":
 		if !reflect.DeepEqual(normalizedActual, normalizedExpected) {
-			return fmt.Errorf("%s assertion failed: expected %v, got %v", context, expected, actual)
+			return fmt.Errorf("%s assertion failed: expected %s, got %s", context, describeValue(expected), describeValue(actual))
 		}
 	case "not_equals", "!=":
 		if reflect.DeepEqual(normalizedActual, normalizedExpected) {
-			return fmt.Errorf("%s assertion failed: expected not %v, got %v", context, expected, actual)
+			return fmt.Errorf("%s assertion failed: expected not %s, got %s", context, describeValue(expected), describeValue(actual))
 		}
 	case "contains":
-		actualStr := fmt.Sprintf("%v", actual)
-		expectedStr := fmt.Sprintf("%v", expected)
-		if !strings.Contains(actualStr, expectedStr) {
-			return fmt.Errorf("%s assertion failed: %v does not contain %v", context, actual, expected)
+		if !ae.containsValue(actual, expected) {
+			return fmt.Errorf("%s assertion failed: %s does not contain %s", context, describeValue(actual), describeValue(expected))
 		}
 	case "not_contains":
-		actualStr := fmt.Sprintf("%v", actual)
-		expectedStr := fmt.Sprintf("%v", expected)
-		if strings.Contains(actualStr, expectedStr) {
-			return fmt.Errorf("%s assertion failed: %v contains %v", context, actual, expected)
+		if ae.containsValue(actual, expected) {
+			return fmt.Errorf("%s assertion failed: %s contains %s", context, describeValue(actual), describeValue(expected))
 		}
 	default:
 		return fmt.Errorf("unsupported operator: %s", operator)
@@ -341,7 +497,73 @@ func (ae *AssertionEngine) compareValues(actual, expected interface{}, operator,
 	return nil
 }
 
+// containsValue reports whether a list holds the expected element or, for
+// any other value, whether its text contains the expected text
+func (ae *AssertionEngine) containsValue(actual, expected interface{}) bool {
+	if elements, ok := actual.([]interface{}); ok {
+		for _, element := range elements {
+			normalizedElement, normalizedExpected := ae.normalizeTypes(element, expected)
+			if reflect.DeepEqual(normalizedElement, normalizedExpected) {
+				return true
+			}
+		}
+	}
+	return strings.Contains(fmt.Sprintf("%v", actual), fmt.Sprintf("%v", expected))
+}
+
+// describeValue formats a value for assertion messages, showing objects and
+// lists as JSON
+func describeValue(value interface{}) string {
+	if isJSONContainer(value) {
+		return formatVariable(value)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func isJSONContainer(value interface{}) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// normalizeJSON converts a value to the types encoding/json decodes to, so
+// that lists and maps from YAML compare equal to those from a response
+func normalizeJSON(value interface{}) interface{} {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// normalizeNumber converts a json.Number, as extracted from a response, to
+// an int if it is one and to a float64 otherwise
+func normalizeNumber(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if n, err := strconv.Atoi(number.String()); err == nil {
+		return n
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
+
 func (ae *AssertionEngine) normalizeTypes(actual, expected interface{}) (interface{}, interface{}) {
+	if isJSONContainer(actual) || isJSONContainer(expected) {
+		return normalizeJSON(actual), normalizeJSON(expected)
+	}
+	actual, expected = normalizeNumber(actual), normalizeNumber(expected)
+
 	actualFloat, actualIsFloat := actual.(float64)
 	expectedFloat, expectedIsFloat := expected.(float64)
 	actualInt, actualIsInt := actual.(int)
@@ -369,7 +591,7 @@ func (ae *AssertionEngine) normalizeTypes(actual, expected interface{}) (interfa
 	return actual, expected
 }
 
-func (ae *AssertionEngine) interpolateAssertion(assertion Assertion, variables map[string]string) Assertion {
+func (ae *AssertionEngine) interpolateAssertion(assertion Assertion, variables map[string]interface{}) Assertion {
 	if variables == nil {
 		return assertion
 	}
@@ -384,28 +606,26 @@ func (ae *AssertionEngine) interpolateAssertion(assertion Assertion, variables m
 	return interpolated
 }
 
-func (ae *AssertionEngine) interpolateExpectedValue(expected interface{}, variables map[string]string) interface{} {
+// interpolateExpectedValue interpolates the expected value of an assertion.
+// An expected value that is a single placeholder takes the type of the
+// variable, so that ${count} compares as a number and ${tags} as a list.
+func (ae *AssertionEngine) interpolateExpectedValue(expected interface{}, variables map[string]interface{}) interface{} {
 	if variables == nil {
 		return expected
 	}
 	
 	switch v := expected.(type) {
 	case string:
-		interpolated := InterpolateVariables(v, variables)
-		
-		if interpolated != v && strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}") {
-			if intVal, err := strconv.Atoi(interpolated); err == nil {
-				return intVal
-			}
-			if floatVal, err := strconv.ParseFloat(interpolated, 64); err == nil {
-				return floatVal
-			}
-			if boolVal, err := strconv.ParseBool(interpolated); err == nil {
-				return boolVal
+		if expression, ok := wholePlaceholder(v); ok {
+			if value, err := resolvePlaceholder(expression, variables); err == nil {
+				if str, isString := value.(string); isString {
+					return parseScalar(str)
+				}
+				return value
 			}
 		}
 		
-		return interpolated
+		return InterpolateVariables(v, variables)
 	case int, float64, bool:
 		return v
 	default:
@@ -417,4 +637,27 @@ func (ae *AssertionEngine) interpolateExpectedValue(expected interface{}, variab
 		}
 		return expected
 	}
-}
\ No newline at end of file
+}
+
+// wholePlaceholder returns the expression of text if text consists of a
+// single placeholder
+func wholePlaceholder(text string) (string, bool) {
+	if !strings.HasPrefix(text, "${") || placeholderEnd(text[2:]) != len(text)-3 {
+		return "", false
+	}
+	return text[2 : len(text)-1], true
+}
+
+// parseScalar converts a string holding a number or boolean to its type
+func parseScalar(s string) interface{} {
+	if intVal, err := strconv.Atoi(s); err == nil {
+		return intVal
+	}
+	if floatVal, err := strconv.ParseFloat(s, 64); err == nil {
+		return floatVal
+	}
+	if boolVal, err := strconv.ParseBool(s); err == nil {
+		return boolVal
+	}
+	return s
+}
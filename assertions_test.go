@@ -1,6 +1,8 @@
 package goresttest
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -701,7 +703,7 @@ func TestAssertionEngine_VariableInterpolation(t *testing.T) {
 		},
 	}
 	
-	variables := map[string]string{
+	variables := map[string]interface{}{
 		"expected_id":     "123",
 		"expected_name":   "John Doe",
 		"expected_status": "active",
@@ -797,7 +799,7 @@ func TestAssertionEngine_VariableInterpolation(t *testing.T) {
 	}
 	
 	// Add field_name variable for one specific test
-	variablesWithField := make(map[string]string)
+	variablesWithField := make(map[string]interface{})
 	for k, v := range variables {
 		variablesWithField[k] = v
 	}
@@ -841,7 +843,7 @@ func TestAssertionEngine_VariableInterpolation_ComplexScenarios(t *testing.T) {
 		Response:   jsonResponse,
 	}
 	
-	variables := map[string]string{
+	variables := map[string]interface{}{
 		"user_index":    "0",
 		"expected_user": "Alice", 
 		"user_id":       "1",
@@ -903,3 +905,111 @@ func TestAssertionEngine_VariableInterpolation_ComplexScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestAssertionEngine_TypedVariables(t *testing.T) {
+	engine := NewAssertionEngine()
+
+	result := &TestResult{
+		StatusCode: 200,
+		Response:   `{"count": 3, "active": true, "roles": ["admin", "beta"], "address": {"city": "Berlin", "zip": 10115}}`,
+	}
+
+	variables := map[string]interface{}{
+		"count":   3,
+		"active":  true,
+		"roles":   []interface{}{"admin", "beta"},
+		"address": map[string]interface{}{"city": "Berlin", "zip": 10115},
+		"other":   []interface{}{"beta"},
+	}
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		wantError bool
+	}{
+		{"int variable", Assertion{Type: "json_path", Path: "count", Expected: "${count}"}, false},
+		{"bool variable", Assertion{Type: "json_path", Path: "active", Expected: "${active}"}, false},
+		{"list variable", Assertion{Type: "json_path", Path: "roles", Expected: "${roles}"}, false},
+		{"map variable", Assertion{Type: "json_path", Path: "address", Expected: "${address}"}, false},
+		{"different list", Assertion{Type: "json_path", Path: "roles", Expected: "${other}"}, true},
+		{"list contains element", Assertion{Type: "json_path", Path: "roles", Expected: "admin", Operator: "contains"}, false},
+		{"list does not contain element", Assertion{Type: "json_path", Path: "roles", Expected: "guest", Operator: "not_contains"}, false},
+		{"yaml list", Assertion{Type: "json_path", Path: "roles", Expected: []interface{}{"admin", "beta"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.runSingleAssertion(result, tt.assertion, variables)
+			if (err != nil) != tt.wantError {
+				t.Errorf("Expected error: %t, got %v", tt.wantError, err)
+			}
+		})
+	}
+
+	err := engine.runSingleAssertion(result, Assertion{Type: "json_path", Path: "roles", Expected: "${other}"}, variables)
+	if err == nil || !strings.Contains(err.Error(), `expected ["beta"], got ["admin","beta"]`) {
+		t.Errorf("Expected lists to be shown as JSON, got %v", err)
+	}
+}
+
+func TestAssertionEngine_ExtractedNumbers(t *testing.T) {
+	engine := NewAssertionEngine()
+
+	extracted := &TestResult{Response: `{"code": 201, "max_ms": 5000, "ratio": 1.5}`}
+	if err := NewVariableExtractor().ExtractVariables(extracted, map[string]string{
+		"code":   "json:code",
+		"max_ms": "json:max_ms",
+		"ratio":  "json:ratio",
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := &TestResult{StatusCode: 201, Duration: 10 * time.Millisecond}
+	tests := []struct {
+		name      string
+		assertion Assertion
+		wantError bool
+	}{
+		{"status code", Assertion{Type: "status_code", Expected: "${code}"}, false},
+		{"status code not equals", Assertion{Type: "status_code", Operator: "!=", Expected: "${code}"}, true},
+		{"response time", Assertion{Type: "response_time", Expected: "${max_ms}"}, false},
+		{"fractional status code", Assertion{Type: "status_code", Expected: "${ratio}"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.runSingleAssertion(result, tt.assertion, extracted.Variables)
+			if (err != nil) != tt.wantError {
+				t.Errorf("Expected error: %t, got %v", tt.wantError, err)
+			}
+		})
+	}
+}
+
+func TestAssertionEngine_JSONPathLargeNumbers(t *testing.T) {
+	engine := NewAssertionEngine()
+	result := &TestResult{Response: `{"id": 9007199254740993, "count": 30, "ratio": 1.5}`}
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		variables map[string]interface{}
+		wantError bool
+	}{
+		{"same id", Assertion{Type: "json_path", Path: "id", Expected: "${id}"}, map[string]interface{}{"id": json.Number("9007199254740993")}, false},
+		{"id differing in the last digit", Assertion{Type: "json_path", Path: "id", Expected: "${id}"}, map[string]interface{}{"id": json.Number("9007199254740992")}, true},
+		{"not equals last digit", Assertion{Type: "json_path", Path: "id", Operator: "!=", Expected: "${id}"}, map[string]interface{}{"id": json.Number("9007199254740992")}, false},
+		{"yaml int", Assertion{Type: "json_path", Path: "count", Expected: 30}, nil, false},
+		{"yaml float", Assertion{Type: "json_path", Path: "count", Expected: float64(30)}, nil, false},
+		{"fraction", Assertion{Type: "json_path", Path: "ratio", Expected: 1.5}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.runSingleAssertion(result, tt.assertion, tt.variables)
+			if (err != nil) != tt.wantError {
+				t.Errorf("Expected error: %t, got %v", tt.wantError, err)
+			}
+		})
+	}
+}
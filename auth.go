@@ -0,0 +1,305 @@
+package goresttest
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// authTypes lists the auth types supported by the HTTPClient
+var authTypes = []string{"basic", "bearer", "api_key", "digest", "hmac", "oauth2", "aws_sigv4", "none"}
+
+// defaultHMACPayload is signed by hmac auth without a payload
+const defaultHMACPayload = "${method}\n${path}\n${timestamp}\n${body}"
+
+// hmacAlgorithms maps the algorithm names of hmac auth to their hash
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// interpolate returns a copy of the auth with fn applied to every field but
+// the hmac payload, which can only be interpolated once the request is known
+func (a *Auth) interpolate(fn func(string) string) *Auth {
+	return &Auth{
+		Type:            fn(a.Type),
+		Username:        fn(a.Username),
+		Password:        fn(a.Password),
+		Token:           fn(a.Token),
+		Key:             fn(a.Key),
+		Name:            fn(a.Name),
+		In:              fn(a.In),
+		Secret:          fn(a.Secret),
+		Algorithm:       fn(a.Algorithm),
+		Encoding:        fn(a.Encoding),
+		Header:          fn(a.Header),
+		Prefix:          fn(a.Prefix),
+		Payload:         a.Payload,
+		TimestampHeader: fn(a.TimestampHeader),
+		TokenURL:        fn(a.TokenURL),
+		GrantType:       fn(a.GrantType),
+		ClientID:        fn(a.ClientID),
+		ClientSecret:    fn(a.ClientSecret),
+		Scope:           fn(a.Scope),
+		ClientAuth:      fn(a.ClientAuth),
+		Region:          fn(a.Region),
+		Service:         fn(a.Service),
+		AccessKeyID:     fn(a.AccessKeyID),
+		SecretAccessKey: fn(a.SecretAccessKey),
+		SessionToken:    fn(a.SessionToken),
+	}
+}
+
+// secrets returns the credentials of the auth, which reports mask
+func (a *Auth) secrets() []string {
+	secrets := []string{a.Password, a.Token, a.Key, a.Secret, a.ClientSecret, a.SecretAccessKey, a.SessionToken}
+	if a.Type == "basic" {
+		secrets = append(secrets, basicCredentials(a.Username, a.Password))
+	}
+	return secrets
+}
+
+// basicCredentials encodes a username and password for basic auth
+func basicCredentials(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+// applyAuth authenticates req, whose body is body, with an interpolated auth.
+// Digest auth needs a challenge from the server and is applied by doDigest,
+// oauth2 auth needs a token and is applied by HTTPClient.doOAuth2.
+func applyAuth(req *http.Request, auth *Auth, body string, variables map[string]interface{}) error {
+	switch auth.Type {
+	case "none", "digest", "oauth2":
+		return nil
+	case "basic":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case "api_key":
+		name := auth.Name
+		if name == "" {
+			name = "X-API-Key"
+		}
+		switch auth.In {
+		case "", "header":
+			req.Header.Set(name, auth.Key)
+		case "query":
+			param := url.QueryEscape(name) + "=" + url.QueryEscape(auth.Key)
+			if req.URL.RawQuery == "" {
+				req.URL.RawQuery = param
+			} else {
+				req.URL.RawQuery += "&" + param
+			}
+		default:
+			return fmt.Errorf("unsupported api_key location %q: expected header or query", auth.In)
+		}
+	case "hmac":
+		return signHMAC(req, auth, body, variables)
+	case "aws_sigv4":
+		return signSigV4(req, auth, body, time.Now())
+	default:
+		return fmt.Errorf("unsupported auth type %q (supported: %s)", auth.Type, strings.Join(authTypes, ", "))
+	}
+	return nil
+}
+
+// signHMAC sets the signature of the request payload and the timestamp it
+// was signed at. The payload can refer to the request with ${method},
+// ${path}, ${query}, ${host}, ${timestamp} and ${body}.
+func signHMAC(req *http.Request, auth *Auth, body string, variables map[string]interface{}) error {
+	algorithm := strings.ToLower(auth.Algorithm)
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	newHash, supported := hmacAlgorithms[algorithm]
+	if !supported {
+		return fmt.Errorf("unsupported hmac algorithm %q: expected sha1, sha256 or sha512", auth.Algorithm)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	payload := auth.Payload
+	if payload == "" {
+		payload = defaultHMACPayload
+	}
+	payload = InterpolateVariables(payload, mergeVariables(variables, map[string]interface{}{
+		"method":    req.Method,
+		"path":      req.URL.EscapedPath(),
+		"query":     req.URL.RawQuery,
+		"host":      req.URL.Host,
+		"timestamp": timestamp,
+		"body":      body,
+	}))
+
+	mac := hmac.New(newHash, []byte(auth.Secret))
+	mac.Write([]byte(payload))
+
+	var signature string
+	switch auth.Encoding {
+	case "", "hex":
+		signature = hex.EncodeToString(mac.Sum(nil))
+	case "base64":
+		signature = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	default:
+		return fmt.Errorf("unsupported hmac encoding %q: expected hex or base64", auth.Encoding)
+	}
+
+	header, timestampHeader := auth.Header, auth.TimestampHeader
+	if header == "" {
+		header = "X-Signature"
+	}
+	if timestampHeader == "" {
+		timestampHeader = "X-Timestamp"
+	}
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(header, auth.Prefix+signature)
+	return nil
+}
+
+// doDigest sends req and, if the server answers with a digest challenge,
+// sends it again with the credentials of auth
+func doDigest(client *http.Client, req *http.Request, auth *Auth) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge := digestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if challenge == nil {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	authorization, err := digestAuthorization(req, auth, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	retry, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", authorization)
+	return client.Do(retry)
+}
+
+// cloneRequest returns a copy of a sent request that can be sent again
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// digestChallenge returns the parameters of the first digest challenge among
+// WWW-Authenticate header values, or nil
+func digestChallenge(values []string) map[string]string {
+	for _, value := range values {
+		scheme, params, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+
+		challenge := make(map[string]string)
+		for params != "" {
+			name, rest, found := strings.Cut(params, "=")
+			if !found {
+				break
+			}
+			rest = strings.TrimSpace(rest)
+
+			var value string
+			if quoted, ok := strings.CutPrefix(rest, `"`); ok {
+				value, rest, _ = strings.Cut(quoted, `"`)
+				_, rest, _ = strings.Cut(rest, ",")
+			} else {
+				value, rest, _ = strings.Cut(rest, ",")
+			}
+			challenge[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+			params = strings.TrimSpace(rest)
+		}
+		return challenge
+	}
+	return nil
+}
+
+// digestAuthorization answers a digest challenge for req as described by
+// RFC 7616, using the auth qop when the server offers it
+func digestAuthorization(req *http.Request, auth *Auth, challenge map[string]string) (string, error) {
+	algorithm := challenge["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	digest := func(parts ...string) string {
+		h := newHash()
+		h.Write([]byte(strings.Join(parts, ":")))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	realm, nonce, uri := challenge["realm"], challenge["nonce"], req.URL.RequestURI()
+	cnonce := make([]byte, 8)
+	if _, err := rand.Read(cnonce); err != nil {
+		return "", err
+	}
+	clientNonce, count := hex.EncodeToString(cnonce), "00000001"
+
+	ha1 := digest(auth.Username, realm, auth.Password)
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = digest(ha1, nonce, clientNonce)
+	}
+	ha2 := digest(req.Method, uri)
+
+	qop := ""
+	for _, offered := range strings.Split(challenge["qop"], ",") {
+		if strings.TrimSpace(offered) == "auth" {
+			qop = "auth"
+		}
+	}
+
+	fields := []string{
+		fmt.Sprintf("username=%q", auth.Username),
+		fmt.Sprintf("realm=%q", realm),
+		fmt.Sprintf("nonce=%q", nonce),
+		fmt.Sprintf("uri=%q", uri),
+		"algorithm=" + algorithm,
+	}
+	if qop != "" {
+		fields = append(fields,
+			fmt.Sprintf("response=%q", digest(ha1, nonce, count, clientNonce, qop, ha2)),
+			"qop="+qop, "nc="+count, fmt.Sprintf("cnonce=%q", clientNonce))
+	} else {
+		fields = append(fields, fmt.Sprintf("response=%q", digest(ha1, nonce, ha2)))
+	}
+	if opaque, exists := challenge["opaque"]; exists {
+		fields = append(fields, fmt.Sprintf("opaque=%q", opaque))
+	}
+	return "Digest " + strings.Join(fields, ", "), nil
+}
@@ -0,0 +1,213 @@
+package goresttest
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// authServer answers 200 on every path when the request carries the
+// credentials that path expects, and 401 otherwise
+func authServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	md5Hex := func(text string) string {
+		sum := md5.Sum([]byte(text))
+		return hex.EncodeToString(sum[:])
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var authorized bool
+		switch r.URL.Path {
+		case "/basic":
+			username, password, ok := r.BasicAuth()
+			authorized = ok && username == "alice" && password == "s3cret"
+		case "/bearer":
+			authorized = r.Header.Get("Authorization") == "Bearer tok3n"
+		case "/api-key":
+			authorized = r.Header.Get("X-Key") == "k3y"
+		case "/api-key-query":
+			authorized = r.URL.Query().Get("page") == "2" && r.URL.Query().Get("api_key") == "k 3y"
+		case "/hmac":
+			mac := hmac.New(sha256.New, []byte("hmac-s3cret"))
+			fmt.Fprintf(mac, "%s\n%s\n%s\n%s", r.Method, r.URL.Path, r.Header.Get("X-Timestamp"), body)
+			authorized = r.Header.Get("X-Signature") == hex.EncodeToString(mac.Sum(nil))
+		case "/digest":
+			params := digestChallenge([]string{r.Header.Get("Authorization")})
+			ha1 := md5Hex("alice:test:s3cret")
+			ha2 := md5Hex(r.Method + ":" + params["uri"])
+			expected := md5Hex(strings.Join([]string{ha1, "n0nce", params["nc"], params["cnonce"], params["qop"], ha2}, ":"))
+			authorized = params != nil && params["response"] == expected && params["opaque"] == "0paque" && string(body) == "payload"
+			if !authorized {
+				w.Header().Set("WWW-Authenticate", `Digest realm="test", qop="auth,auth-int", nonce="n0nce", opaque="0paque"`)
+			}
+		}
+
+		if !authorized {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("welcome"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPClient_ExecuteRequest_Auth(t *testing.T) {
+	server := authServer(t)
+	client := NewHTTPClient(server.URL)
+
+	variables := map[string]interface{}{"user": "alice", "password": "s3cret"}
+
+	tests := []struct {
+		url  string
+		auth Auth
+	}{
+		{"/basic", Auth{Type: "basic", Username: "${user}", Password: "${password}"}},
+		{"/bearer", Auth{Type: "bearer", Token: "tok3n"}},
+		{"/api-key", Auth{Type: "api_key", Name: "X-Key", Key: "k3y"}},
+		{"/api-key-query?page=2", Auth{Type: "api_key", In: "query", Name: "api_key", Key: "k 3y"}},
+		{"/hmac", Auth{Type: "hmac", Secret: "hmac-s3cret"}},
+		{"/digest", Auth{Type: "digest", Username: "${user}", Password: "${password}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.auth.Type, func(t *testing.T) {
+			auth := tt.auth
+			test := Test{Name: tt.url, Method: "POST", URL: tt.url, Body: "payload", Auth: &auth}
+
+			result, err := client.ExecuteRequest(test, variables)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.StatusCode != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", result.StatusCode)
+			}
+		})
+	}
+
+	result, err := client.ExecuteRequest(Test{Name: "Unknown", URL: "/", Auth: &Auth{Type: "kerberos"}}, nil)
+	if err == nil || !strings.Contains(result.Error, `unsupported auth type "kerberos"`) {
+		t.Errorf("Expected unsupported auth type error, got %v", err)
+	}
+}
+
+func TestTestRunner_RunTestSuite_Auth(t *testing.T) {
+	server := authServer(t)
+
+	suite := &TestSuite{
+		Variables: map[string]interface{}{"token": "tok3n"},
+		Auth:      &Auth{Type: "bearer", Token: "${token}"},
+		Tests: []Test{
+			{Name: "Suite Auth", URL: "/bearer", Assertions: statusOK()},
+			{Name: "Test Auth", URL: "/api-key", Auth: &Auth{Type: "api_key", Name: "X-Key", Key: "k3y"}, Assertions: statusOK()},
+			{
+				Name:       "No Auth",
+				URL:        "/bearer",
+				Auth:       &Auth{Type: "none"},
+				Assertions: []Assertion{{Type: "status_code", Expected: 401}},
+			},
+		},
+	}
+
+	results, err := NewTestRunner(server.URL).RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != StatusPassed {
+			t.Errorf("Expected %s to pass, got %s: %s", result.Name, result.Status, result.Error)
+		}
+	}
+}
+
+func TestReporter_MasksSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"echo": "` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer server.Close()
+
+	suite := &TestSuite{
+		Variables: map[string]interface{}{"token": "tok3n-value"},
+		Tests: []Test{{
+			Name:       "Echo",
+			URL:        "/echo",
+			Auth:       &Auth{Type: "bearer", Token: "${token}"},
+			Extract:    map[string]string{"echo": "json:echo"},
+			Assertions: []Assertion{{Type: "body_contains", Expected: "missing"}},
+		}},
+	}
+
+	results, err := NewTestRunner(server.URL).RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Variables["echo"] != "Bearer tok3n-value" {
+		t.Fatalf("Expected results to keep the credentials, got %v", results[0].Variables["echo"])
+	}
+
+	filename := filepath.Join(t.TempDir(), "report.json")
+	if err := NewReporter().GenerateJSONReport(results, filename); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	report, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if strings.Contains(string(report), "tok3n-value") {
+		t.Errorf("Expected the token to be masked, got %s", report)
+	}
+	if !strings.Contains(string(report), "Bearer ****") {
+		t.Errorf("Expected the masked token in the report, got %s", report)
+	}
+}
+
+func TestParseTestSuiteFromString_Auth(t *testing.T) {
+	yamlContent := `
+name: "Auth"
+auth:
+  type: "basic"
+  username: "${user}"
+  password: "${env:PASSWORD}"
+tests:
+  - name: "Signed ${id}"
+    url: "/items/${id}"
+    auth:
+      type: "hmac"
+      secret: "${secret}"
+      payload: "${method} ${path} ${id}"
+    data:
+      - id: "1"
+  - name: "Unknown"
+    url: "/"
+    auth:
+      type: "oauth"
+`
+
+	_, err := ParseTestSuiteFromString(yamlContent)
+	if err == nil || !strings.Contains(err.Error(), `unknown auth type "oauth"`) {
+		t.Fatalf("Expected unknown auth type error, got %v", err)
+	}
+
+	suite, err := ParseTestSuiteFromString(strings.SplitN(yamlContent, "  - name: \"Unknown\"", 2)[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if suite.Auth == nil || suite.Auth.Type != "basic" || suite.Auth.Password != "${env:PASSWORD}" {
+		t.Errorf("Expected suite basic auth, got %+v", suite.Auth)
+	}
+	if got := suite.Tests[0].variables["id"]; got != "1" {
+		t.Errorf("Expected the row variables of the instance, got %v", suite.Tests[0].variables)
+	}
+}
@@ -2,36 +2,152 @@ package goresttest
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
+// DefaultTimeout is the request timeout used for tests that do not set one
+const DefaultTimeout = 30 * time.Second
+
 // HTTPClient handles HTTP requests for tests
 type HTTPClient struct {
 	client  *http.Client
 	baseURL string
+	timeout time.Duration
+	// strictVariables fails requests that use unresolved placeholders
+	strictVariables bool
+	// cookies gives requests of tests without a session the default
+	// cookie jar
+	cookies  bool
+	sessions map[string]http.CookieJar
+	mutex    sync.Mutex
+	// tokens caches OAuth2 access tokens by auth configuration
+	tokens     map[string]*oauth2Token
+	tokenMutex sync.Mutex
 }
 
 // NewHTTPClient creates a new HTTPClient with the specified base URL
 func NewHTTPClient(baseURL string) *HTTPClient {
 	return &HTTPClient{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:  &http.Client{},
 		baseURL: baseURL,
+		timeout: DefaultTimeout,
+	}
+}
+
+// SetDefaultTimeout sets the timeout applied to requests whose test does not
+// set one. Timeouts are applied per request, so the client is safe to share
+// between parallel tests.
+func (c *HTTPClient) SetDefaultTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// SetStrictVariables makes requests fail, before they are sent, if their URL,
+// headers or body contain placeholders that cannot be resolved
+func (c *HTTPClient) SetStrictVariables(strict bool) {
+	c.strictVariables = strict
+}
+
+// EnableCookies makes the requests of tests that do not name a session share
+// a cookie jar. Tests with a session always use the jar of their session.
+func (c *HTTPClient) EnableCookies(enabled bool) {
+	c.cookies = enabled
+}
+
+// ResetSessions discards the cookies of every session
+func (c *HTTPClient) ResetSessions() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.sessions = nil
+}
+
+// sessionJar returns the cookie jar of a session, creating it on first use.
+// The unnamed session has a jar only if cookies are enabled.
+func (c *HTTPClient) sessionJar(session string) http.CookieJar {
+	if session == "" && !c.cookies {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.sessions == nil {
+		c.sessions = make(map[string]http.CookieJar)
+	}
+	jar, exists := c.sessions[session]
+	if !exists {
+		// cookiejar.New only fails for invalid options
+		jar, _ = cookiejar.New(nil)
+		c.sessions[session] = jar
 	}
+	return jar
 }
 
 // ExecuteRequest executes a test request and returns the result
-func (c *HTTPClient) ExecuteRequest(test Test, variables map[string]string) (*TestResult, error) {
+func (c *HTTPClient) ExecuteRequest(test Test, variables map[string]interface{}) (*TestResult, error) {
+	return c.ExecuteRequestContext(context.Background(), test, variables)
+}
+
+// ExecuteRequestContext executes a test request bound to ctx, so that
+// cancelling ctx aborts the request. The auth of the test is applied once
+// the request is interpolated, and its credentials are recorded in the
+// result so that reports can mask them.
+func (c *HTTPClient) ExecuteRequestContext(ctx context.Context, test Test, variables map[string]interface{}) (result *TestResult, err error) {
+	timeout := test.Timeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	start := time.Now()
+
+	var unresolved []string
+	interpolate := func(text string) string {
+		interpolated, missing := interpolateStrict(text, variables)
+		unresolved = append(unresolved, missing...)
+		return interpolated
+	}
 	
 	url := c.buildURL(test.URL)
-	url = InterpolateVariables(url, variables)
+	url = interpolate(url)
+
+	headers := make(map[string]string, len(test.Headers))
+	for key, value := range test.Headers {
+		headers[key] = interpolate(value)
+	}
+
+	var auth *Auth
+	var secrets []string
+	if test.Auth != nil {
+		auth = test.Auth.interpolate(interpolate)
+		auth.awsEnvironmentDefaults()
+		secrets = auth.secrets()
+		defer func() {
+			if result != nil {
+				result.secrets = secrets
+			}
+		}()
+	}
+
+	// JSON bodies are interpolated so that they stay valid JSON
+	interpolateBody := func(text string) string {
+		if !isJSONBody(headers, text) {
+			return interpolate(text)
+		}
+		interpolated, missing := interpolateJSONStrict(text, variables)
+		unresolved = append(unresolved, missing...)
+		return interpolated
+	}
 	
 	method := strings.ToUpper(test.Method)
 	if method == "" {
@@ -39,6 +155,7 @@ func (c *HTTPClient) ExecuteRequest(test Test, variables map[string]string) (*Te
 	}
 
 	var body io.Reader
+	var bodyStr string
 	if test.Body != "" && test.BodyFile != "" {
 		return &TestResult{
 			Name:    test.Name,
@@ -48,10 +165,10 @@ func (c *HTTPClient) ExecuteRequest(test Test, variables map[string]string) (*Te
 	}
 	
 	if test.Body != "" {
-		bodyStr := InterpolateVariables(test.Body, variables)
+		bodyStr = interpolateBody(test.Body)
 		body = bytes.NewBufferString(bodyStr)
 	} else if test.BodyFile != "" {
-		bodyFilePath := InterpolateVariables(test.BodyFile, variables)
+		bodyFilePath := interpolate(test.BodyFile)
 		bodyContent, err := os.ReadFile(bodyFilePath)
 		if err != nil {
 			return &TestResult{
@@ -60,11 +177,26 @@ func (c *HTTPClient) ExecuteRequest(test Test, variables map[string]string) (*Te
 				Error:   fmt.Sprintf("failed to read body file '%s': %v", bodyFilePath, err),
 			}, fmt.Errorf("failed to read body file '%s': %w", bodyFilePath, err)
 		}
-		bodyStr := InterpolateVariables(string(bodyContent), variables)
+		bodyStr = interpolateBody(string(bodyContent))
 		body = bytes.NewBufferString(bodyStr)
 	}
 
-	req, err := http.NewRequest(method, url, body)
+	if c.strictVariables && len(unresolved) > 0 {
+		var placeholders []string
+		for _, placeholder := range unresolved {
+			if !containsString(placeholders, placeholder) {
+				placeholders = append(placeholders, placeholder)
+			}
+		}
+		err := &UnresolvedPlaceholdersError{Placeholders: placeholders}
+		return &TestResult{
+			Name:    test.Name,
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+
+	req, err := http.NewRequestWithContext(requestCtx, method, url, body)
 	if err != nil {
 		return &TestResult{
 			Name:    test.Name,
@@ -73,19 +205,44 @@ func (c *HTTPClient) ExecuteRequest(test Test, variables map[string]string) (*Te
 		}, err
 	}
 
-	for key, value := range test.Headers {
-		interpolatedValue := InterpolateVariables(value, variables)
-		req.Header.Set(key, interpolatedValue)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if auth != nil {
+		if err := applyAuth(req, auth, bodyStr, variables); err != nil {
+			return &TestResult{
+				Name:    test.Name,
+				Success: false,
+				Error:   fmt.Sprintf("failed to authenticate request: %v", err),
+			}, err
+		}
 	}
 
-	if test.Timeout > 0 {
-		c.client.Timeout = test.Timeout
+	client := c.client
+	if jar := c.sessionJar(test.Session); jar != nil {
+		withJar := *c.client
+		withJar.Jar = jar
+		client = &withJar
 	}
 
-	resp, err := c.client.Do(req)
+	var resp *http.Response
+	switch {
+	case auth != nil && auth.Type == "digest":
+		resp, err = doDigest(client, req, auth)
+	case auth != nil && auth.Type == "oauth2":
+		var tokens []string
+		resp, tokens, err = c.doOAuth2(client, req, auth)
+		secrets = append(secrets, tokens...)
+	default:
+		resp, err = client.Do(req)
+	}
 	duration := time.Since(start)
 	
 	if err != nil {
+		if timedOut(ctx, requestCtx) {
+			return timeoutResult(test.Name, 0, duration, nil, timeout)
+		}
 		return &TestResult{
 			Name:     test.Name,
 			Success:  false,
@@ -97,6 +254,9 @@ func (c *HTTPClient) ExecuteRequest(test Test, variables map[string]string) (*Te
 
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
+		if timedOut(ctx, requestCtx) {
+			return timeoutResult(test.Name, resp.StatusCode, time.Since(start), resp.Header, timeout)
+		}
 		return &TestResult{
 			Name:       test.Name,
 			Success:    false,
@@ -107,19 +267,49 @@ func (c *HTTPClient) ExecuteRequest(test Test, variables map[string]string) (*Te
 		}, err
 	}
 
-	result := &TestResult{
+	result = &TestResult{
 		Name:       test.Name,
 		Success:    true,
 		StatusCode: resp.StatusCode,
 		Duration:   duration,
 		Response:   string(responseBody),
 		Headers:    resp.Header,
-		Variables:  make(map[string]string),
+		Variables:  make(map[string]interface{}),
+	}
+	if resp.TLS != nil {
+		result.TLSVersion = tls.VersionName(resp.TLS.Version)
+		if len(resp.TLS.PeerCertificates) > 0 {
+			result.CertificateExpiry = resp.TLS.PeerCertificates[0].NotAfter
+		}
 	}
 
 	return result, nil
 }
 
+// UnresolvedPlaceholdersError is returned in strict mode for a request that
+// uses placeholders without a value
+type UnresolvedPlaceholdersError struct {
+	Placeholders []string
+}
+
+func (e *UnresolvedPlaceholdersError) Error() string {
+	return "unresolved placeholders: " + strings.Join(e.Placeholders, ", ")
+}
+
+// isJSONBody reports whether a request body is JSON, either because of its
+// Content-Type header or, without one, because it starts like a JSON object
+// or array
+func isJSONBody(headers map[string]string, body string) bool {
+	for key, value := range headers {
+		if strings.EqualFold(key, "Content-Type") {
+			mediaType := strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0]))
+			return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+		}
+	}
+	trimmed := strings.TrimSpace(body)
+	return strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")
+}
+
 func (c *HTTPClient) buildURL(path string) string {
 	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
 		return path
@@ -133,4 +323,23 @@ func (c *HTTPClient) buildURL(path string) string {
 	}
 	
 	return fmt.Sprintf("%s/%s", baseURL, path)
-}
\ No newline at end of file
+}
+
+// timedOut reports whether requestCtx expired because of the request timeout
+// rather than because the parent context was cancelled.
+func timedOut(parent, requestCtx context.Context) bool {
+	return parent.Err() == nil && errors.Is(requestCtx.Err(), context.DeadlineExceeded)
+}
+
+func timeoutResult(name string, statusCode int, duration time.Duration, headers http.Header, timeout time.Duration) (*TestResult, error) {
+	err := fmt.Errorf("request timed out after %v", timeout)
+	return &TestResult{
+		Name:       name,
+		Success:    false,
+		StatusCode: statusCode,
+		Duration:   duration,
+		Headers:    headers,
+		Error:      err.Error(),
+		TimedOut:   true,
+	}, err
+}
@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestHTTPClient_ExecuteRequest_BodyFile(t *testing.T) {
@@ -35,7 +37,7 @@ func TestHTTPClient_ExecuteRequest_BodyFile(t *testing.T) {
 	tests := []struct {
 		name      string
 		test      Test
-		variables map[string]string
+		variables map[string]interface{}
 		wantError bool
 		checkBody bool
 		expected  string
@@ -48,7 +50,7 @@ func TestHTTPClient_ExecuteRequest_BodyFile(t *testing.T) {
 				URL:      "/test",
 				BodyFile: bodyFile,
 			},
-			variables: map[string]string{
+			variables: map[string]interface{}{
 				"user_id": "123",
 			},
 			wantError: false,
@@ -63,7 +65,7 @@ func TestHTTPClient_ExecuteRequest_BodyFile(t *testing.T) {
 				URL:      "/test",
 				BodyFile: "/non/existent/file.json",
 			},
-			variables: map[string]string{},
+			variables: map[string]interface{}{},
 			wantError: true,
 		},
 		{
@@ -75,7 +77,7 @@ func TestHTTPClient_ExecuteRequest_BodyFile(t *testing.T) {
 				Body:     "inline body",
 				BodyFile: bodyFile,
 			},
-			variables: map[string]string{},
+			variables: map[string]interface{}{},
 			wantError: true,
 		},
 		{
@@ -86,7 +88,7 @@ func TestHTTPClient_ExecuteRequest_BodyFile(t *testing.T) {
 				URL:      "/test",
 				BodyFile: "${temp_dir}/test_body.json",
 			},
-			variables: map[string]string{
+			variables: map[string]interface{}{
 				"temp_dir": tempDir,
 				"user_id":  "456",
 			},
@@ -153,7 +155,7 @@ func TestHTTPClient_ExecuteRequest_InlineBody(t *testing.T) {
 		Body:   `{"name": "${user_name}", "active": true}`,
 	}
 
-	variables := map[string]string{
+	variables := map[string]interface{}{
 		"user_name": "John Doe",
 	}
 
@@ -193,7 +195,7 @@ func TestHTTPClient_ExecuteRequest_NoBody(t *testing.T) {
 		URL:    "/test",
 	}
 
-	result, err := client.ExecuteRequest(test, map[string]string{})
+	result, err := client.ExecuteRequest(test, map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -210,3 +212,149 @@ func TestHTTPClient_ExecuteRequest_NoBody(t *testing.T) {
 		t.Errorf("Expected response to contain method GET, got %q", result.Response)
 	}
 }
+
+func TestHTTPClient_ExecuteRequest_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+
+	// Run a short and a long timeout concurrently to make sure one test's
+	// timeout does not leak into the other.
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		timedOut bool
+	}{
+		{name: "short", timeout: 50 * time.Millisecond, timedOut: true},
+		{name: "long", timeout: 2 * time.Second, timedOut: false},
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*TestResult, len(tests))
+	errs := make([]error, len(tests))
+	for i, tt := range tests {
+		wg.Add(1)
+		go func(i int, timeout time.Duration) {
+			defer wg.Done()
+			results[i], errs[i] = client.ExecuteRequest(Test{Name: tests[i].name, URL: "/", Timeout: timeout}, nil)
+		}(i, tt.timeout)
+	}
+	wg.Wait()
+
+	for i, tt := range tests {
+		if results[i].TimedOut != tt.timedOut {
+			t.Errorf("%s: expected TimedOut %t, got %t (error: %v)", tt.name, tt.timedOut, results[i].TimedOut, errs[i])
+		}
+		if tt.timedOut && !strings.Contains(results[i].Error, "timed out") {
+			t.Errorf("%s: expected timeout error, got %q", tt.name, results[i].Error)
+		}
+		if !tt.timedOut && (errs[i] != nil || results[i].StatusCode != 200) {
+			t.Errorf("%s: expected success, got status %d, error %v", tt.name, results[i].StatusCode, errs[i])
+		}
+	}
+}
+
+// sessionServer logs a user in on /login?user=NAME with a session cookie and
+// answers /me with the name of the logged in user, or 401
+func sessionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "token-" + r.URL.Query().Get("user"), Path: "/"})
+		case "/me":
+			cookie, err := r.Cookie("session")
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(strings.TrimPrefix(cookie.Value, "token-")))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTestRunner_RunTestSuite_Sessions(t *testing.T) {
+	server := sessionServer(t)
+
+	suite := &TestSuite{
+		Cookies: true,
+		Tests: []Test{
+			{
+				Name:       "Login",
+				URL:        "/login?user=alice",
+				Extract:    map[string]string{"token": "cookie:session"},
+				Assertions: []Assertion{{Type: "cookie", Path: "session", Expected: "token-alice"}},
+			},
+			{Name: "Login Admin", URL: "/login?user=root", Session: "admin"},
+			{
+				Name:       "Me",
+				URL:        "/me",
+				DependsOn:  []string{"Login"},
+				Assertions: []Assertion{statusOK()[0], {Type: "body_contains", Expected: "alice"}},
+			},
+			{
+				Name:       "Me Admin",
+				URL:        "/me",
+				Session:    "admin",
+				DependsOn:  []string{"Login Admin"},
+				Assertions: []Assertion{statusOK()[0], {Type: "body_contains", Expected: "root"}},
+			},
+			{
+				Name:       "Me Anonymous",
+				URL:        "/me",
+				Session:    "anonymous",
+				Assertions: []Assertion{{Type: "status_code", Expected: 401}},
+			},
+		},
+	}
+
+	runner := NewTestRunner(server.URL)
+	results, err := runner.RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != StatusPassed {
+			t.Errorf("Expected %s to pass, got %s: %s", result.Name, result.Status, result.Error)
+		}
+	}
+	if token := results[0].Variables["token"]; token != "token-alice" {
+		t.Errorf("Expected extracted cookie token-alice, got %v", token)
+	}
+
+	// Every run starts without cookies
+	suite.Tests = []Test{{Name: "Me", URL: "/me", Assertions: []Assertion{{Type: "status_code", Expected: 401}}}}
+	results, err = runner.RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Status != StatusPassed {
+		t.Errorf("Expected cookies to be reset between runs, got %s: %s", results[0].Status, results[0].Error)
+	}
+}
+
+func TestHTTPClient_ExecuteRequest_CookiesDisabled(t *testing.T) {
+	server := sessionServer(t)
+	client := NewHTTPClient(server.URL)
+
+	if _, err := client.ExecuteRequest(Test{Name: "Login", URL: "/login?user=alice"}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := client.ExecuteRequest(Test{Name: "Me", URL: "/me"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected cookies not to be kept without a jar, got status %d", result.StatusCode)
+	}
+}
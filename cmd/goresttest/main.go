@@ -1,36 +1,70 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/the-sumeet/goresttest"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:]))
+	}
+
 	var (
 		configFile   = flag.String("config", "", "Path to YAML test configuration file")
+		environment  = flag.String("env", "", "Run against one of the suite's environments")
 		outputFormat = flag.String("output", "console", "Output format: console, json, html")
 		outputFile   = flag.String("file", "", "Output file path (for json/html formats)")
 		parallel     = flag.Bool("parallel", false, "Run tests in parallel")
 		maxWorkers   = flag.Int("workers", 10, "Maximum number of parallel workers")
+		failFast     = flag.Bool("fail-fast", false, "Stop starting new tests after the first failure")
+		maxFailures  = flag.Int("max-failures", 0, "Stop starting new tests after N failures (0 = no limit)")
+		tags         = flag.String("tags", "", "Only run tests with any of these comma separated tags")
+		excludeTags  = flag.String("exclude-tags", "", "Skip tests with any of these comma separated tags")
+		runPattern   = flag.String("run", "", "Only run tests whose name matches this regular expression")
+		strict       = flag.Bool("strict", false, "Fail tests whose request uses an undefined variable")
+		caCert       = flag.String("ca-cert", "", "PEM bundle of CAs to trust in addition to the system roots")
+		clientCert   = flag.String("client-cert", "", "PEM client certificate for mutual TLS")
+		clientKey    = flag.String("client-key", "", "PEM private key of the client certificate")
+		serverName   = flag.String("tls-server-name", "", "Host name to verify the server certificate against")
+		minTLS       = flag.String("tls-min-version", "", "Lowest accepted TLS version: 1.0, 1.1, 1.2 or 1.3")
+		insecure     = flag.Bool("insecure", false, "Skip verification of server certificates")
 		verbose      = flag.Bool("verbose", false, "Verbose output")
 		version      = flag.Bool("version", false, "Show version information")
 	)
 	
+	variables := variableFlag{}
+	var varFiles, envFiles listFlag
+	flag.Var(variables, "var", "Set a variable as key=value (repeatable)")
+	flag.Var(&varFiles, "var-file", "Read variables from a YAML file (repeatable)")
+	flag.Var(&envFiles, "env-file", "Load environment variables from a .env file (repeatable, default: .env next to the config file)")
+	
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s validate <file>...\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "GoRestTest - API Testing Framework\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s -config tests.yaml\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -config tests.yaml -parallel -workers 5\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config tests.yaml -env staging\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config tests.yaml -var-file secrets.yaml -var user_id=42\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -config tests.yaml -output html -file report.html\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config tests.yaml -fail-fast\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config tests.yaml -tags smoke,auth -exclude-tags slow\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config tests.yaml -ca-cert ca.pem -client-cert client.pem -client-key client-key.pem\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s validate tests.yaml\n", os.Args[0])
 	}
 	
 	flag.Parse()
@@ -57,7 +91,37 @@ func main() {
 		log.Fatalf("Failed to parse test suite: %v", err)
 	}
 	
-	// Apply CLI overrides
+	// Values in .env files are only used for ${env:NAME} lookups of
+	// variables that are not already set in the environment
+	if len(envFiles) == 0 {
+		defaultEnvFile := filepath.Join(filepath.Dir(*configFile), ".env")
+		if _, err := os.Stat(defaultEnvFile); err == nil {
+			envFiles = append(envFiles, defaultEnvFile)
+		}
+	}
+	for _, envFile := range envFiles {
+		if err := goresttest.LoadEnvFile(envFile); err != nil {
+			log.Fatalf("Failed to load env file: %v", err)
+		}
+	}
+	
+	// Apply CLI overrides. Variables are applied in increasing precedence:
+	// suite variables, the environment, -var-file files in order, then -var.
+	if *environment != "" {
+		if err := suite.ApplyEnvironment(*environment); err != nil {
+			log.Fatalf("Invalid -env: %v", err)
+		}
+	}
+	
+	for _, varFile := range varFiles {
+		fileVariables, err := goresttest.LoadVariablesFile(varFile)
+		if err != nil {
+			log.Fatalf("Failed to load variables: %v", err)
+		}
+		suite.OverrideVariables(fileVariables)
+	}
+	suite.OverrideVariables(variables)
+	
 	if *parallel {
 		suite.Parallel = true
 		if *maxWorkers > 0 {
@@ -65,10 +129,66 @@ func main() {
 		}
 	}
 	
+	if *failFast {
+		suite.FailFast = true
+	}
+	
+	if *maxFailures > 0 {
+		suite.MaxFailures = *maxFailures
+	}
+	
+	if *strict {
+		suite.StrictVariables = true
+	}
+	
+	if *caCert != "" || *clientCert != "" || *clientKey != "" || *serverName != "" || *minTLS != "" || *insecure {
+		if suite.TLS == nil {
+			suite.TLS = &goresttest.TLSConfig{}
+		}
+		if *caCert != "" {
+			suite.TLS.CACert = *caCert
+		}
+		if *clientCert != "" {
+			suite.TLS.ClientCert = *clientCert
+		}
+		if *clientKey != "" {
+			suite.TLS.ClientKey = *clientKey
+		}
+		if *serverName != "" {
+			suite.TLS.ServerName = *serverName
+		}
+		if *minTLS != "" {
+			suite.TLS.MinVersion = *minTLS
+		}
+		if *insecure {
+			suite.TLS.InsecureSkipVerify = true
+		}
+	}
+	
+	if *tags != "" || *excludeTags != "" || *runPattern != "" {
+		filter := goresttest.TestFilter{
+			Tags:        goresttest.ParseTagList(*tags),
+			ExcludeTags: goresttest.ParseTagList(*excludeTags),
+		}
+		if *runPattern != "" {
+			filter.Name, err = regexp.Compile(*runPattern)
+			if err != nil {
+				log.Fatalf("Invalid -run pattern: %v", err)
+			}
+		}
+		suite.Tests = goresttest.FilterTests(suite.Tests, filter)
+	}
+	
 	if *verbose {
 		fmt.Printf("Loaded test suite: %s\n", suite.Name)
+		if suite.Environment != "" {
+			fmt.Printf("Environment: %s\n", suite.Environment)
+		}
 		fmt.Printf("Base URL: %s\n", suite.BaseURL)
 		fmt.Printf("Tests: %d\n", len(suite.Tests))
+		if len(suite.Setup) > 0 || len(suite.Teardown) > 0 {
+			fmt.Printf("Setup: %d, Teardown: %d\n", len(suite.Setup), len(suite.Teardown))
+		}
 		fmt.Printf("Parallel: %t\n", suite.Parallel)
 		if suite.Parallel {
 			fmt.Printf("Max Workers: %d\n", suite.MaxWorkers)
@@ -78,15 +198,29 @@ func main() {
 	
 	var testResults []*goresttest.TestResult
 	
-	if len(suite.Tests) > 0 {
+	// Ctrl-C stops dispatching new tests and aborts in-flight requests, so a
+	// partial report is still produced. Signal handling is reset as soon as
+	// the first Ctrl-C arrives, so a second one kills the process, e.g. while
+	// teardown is hanging.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	context.AfterFunc(ctx, stop)
+	
+	if len(suite.Setup)+len(suite.Tests)+len(suite.Teardown) > 0 {
 		// Create a test runner and execute the suite
 		runner := goresttest.NewTestRunner(suite.BaseURL)
-		testResults, err = runner.RunTestSuite(suite)
+		testResults, err = runner.RunTestSuiteContext(ctx, suite)
 		if err != nil {
 			log.Fatalf("Failed to execute tests: %v", err)
 		}
 	}
 	
+	interrupted := ctx.Err() != nil
+	stop()
+	
+	if interrupted {
+		fmt.Fprintln(os.Stderr, "Interrupted: reporting partial results")
+	}
+	
 	// Generate reports using the library
 	reporter := goresttest.NewReporter()
 	
@@ -127,11 +261,73 @@ func main() {
 	// Set exit code based on test results
 	exitCode := 0
 	for _, result := range testResults {
-		if !result.Success {
+		if result.Status == goresttest.StatusFailed || result.Status == goresttest.StatusCancelled {
 			exitCode = 1
 			break
 		}
 	}
 	
 	os.Exit(exitCode)
-}
\ No newline at end of file
+}
+
+// validate checks suite files without running them, printing every problem
+// found, and returns the exit code
+func validate(files []string) int {
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s validate <file>...\n", os.Args[0])
+		return 2
+	}
+
+	exitCode := 0
+	for _, file := range files {
+		err := goresttest.ValidateTestSuiteFile(file)
+		if err == nil {
+			fmt.Printf("%s: ok\n", file)
+			continue
+		}
+
+		exitCode = 1
+		var problems goresttest.ValidationErrors
+		if errors.As(err, &problems) {
+			for _, problem := range problems {
+				fmt.Println(problem.Error())
+			}
+		} else {
+			fmt.Printf("%s: %v\n", file, err)
+		}
+	}
+
+	return exitCode
+}
+
+// variableFlag collects repeated key=value flags. Values are strings.
+type variableFlag map[string]interface{}
+
+func (f variableFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for key, value := range f {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f variableFlag) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if !found || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	f[key] = val
+	return nil
+}
+
+// listFlag collects repeated flags
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
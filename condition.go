@@ -0,0 +1,309 @@
+package goresttest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// conditionalSkip returns a skipped result if the skip_if or run_if condition
+// of test says that it should not run, or nil if it should.
+func conditionalSkip(test Test, variables map[string]interface{}) (*TestResult, error) {
+	if test.SkipIf != "" {
+		skip, err := evaluateCondition(test.SkipIf, variables)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skip_if condition %q: %w", test.SkipIf, err)
+		}
+		if skip {
+			return &TestResult{
+				Name:       test.Name,
+				Status:     StatusSkipped,
+				SkipReason: fmt.Sprintf("skip_if condition %q is true", test.SkipIf),
+			}, nil
+		}
+	}
+
+	if test.RunIf != "" {
+		run, err := evaluateCondition(test.RunIf, variables)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run_if condition %q: %w", test.RunIf, err)
+		}
+		if !run {
+			return &TestResult{
+				Name:       test.Name,
+				Status:     StatusSkipped,
+				SkipReason: fmt.Sprintf("run_if condition %q is false", test.RunIf),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// evaluateCondition evaluates a skip_if/run_if expression against variables.
+//
+// Expressions combine operands with ==, !=, <, <=, >, >=, !, && and ||, and
+// may use parentheses. Operands are ${name} variable references, which may
+// have a default or call a function like any other placeholder, quoted
+// strings or bare words. Variables that are not defined evaluate to an empty
+// string, while functions and defaults that fail make the condition
+// invalid. An operand on its own is true unless it is empty, "false", "0",
+// "no" or "off". Ordering comparisons are numeric when both sides are
+// numbers.
+func evaluateCondition(expression string, variables map[string]interface{}) (bool, error) {
+	tokens, err := tokenizeCondition(expression)
+	if err != nil {
+		return false, err
+	}
+
+	parser := &conditionParser{tokens: tokens, variables: variables}
+	result, err := parser.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return false, fmt.Errorf("unexpected %q in condition", parser.tokens[parser.pos].text)
+	}
+	return result, nil
+}
+
+type conditionTokenKind int
+
+const (
+	tokenOperand conditionTokenKind = iota
+	tokenVariable
+	tokenOperator
+)
+
+type conditionToken struct {
+	kind conditionTokenKind
+	text string
+}
+
+var conditionOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")"}
+
+func tokenizeCondition(expression string) ([]conditionToken, error) {
+	var tokens []conditionToken
+
+	for i := 0; i < len(expression); {
+		c := expression[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+			continue
+		case strings.HasPrefix(expression[i:], "${"):
+			end := placeholderEnd(expression[i+2:])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated variable reference in condition")
+			}
+			tokens = append(tokens, conditionToken{kind: tokenVariable, text: expression[i+2 : i+2+end]})
+			i += end + 3
+			continue
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expression[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in condition")
+			}
+			tokens = append(tokens, conditionToken{kind: tokenOperand, text: expression[i+1 : i+1+end]})
+			i += end + 2
+			continue
+		}
+
+		if operator := matchConditionOperator(expression[i:]); operator != "" {
+			tokens = append(tokens, conditionToken{kind: tokenOperator, text: operator})
+			i += len(operator)
+			continue
+		}
+
+		start := i
+		for i < len(expression) && !strings.ContainsRune(" \t\n\"'", rune(expression[i])) &&
+			matchConditionOperator(expression[i:]) == "" && !strings.HasPrefix(expression[i:], "${") {
+			i++
+		}
+		tokens = append(tokens, conditionToken{kind: tokenOperand, text: expression[start:i]})
+	}
+
+	return tokens, nil
+}
+
+func matchConditionOperator(text string) string {
+	for _, operator := range conditionOperators {
+		if strings.HasPrefix(text, operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+type conditionParser struct {
+	tokens    []conditionToken
+	pos       int
+	variables map[string]interface{}
+}
+
+func (p *conditionParser) peekOperator(operators ...string) string {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOperator {
+		return ""
+	}
+	for _, operator := range operators {
+		if p.tokens[p.pos].text == operator {
+			return operator
+		}
+	}
+	return ""
+}
+
+func (p *conditionParser) parseOr() (bool, error) {
+	result, err := p.parseAnd()
+	if err != nil {
+		return false, err
+	}
+	for p.peekOperator("||") != "" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return false, err
+		}
+		result = result || right
+	}
+	return result, nil
+}
+
+func (p *conditionParser) parseAnd() (bool, error) {
+	result, err := p.parseUnary()
+	if err != nil {
+		return false, err
+	}
+	for p.peekOperator("&&") != "" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return false, err
+		}
+		result = result && right
+	}
+	return result, nil
+}
+
+func (p *conditionParser) parseUnary() (bool, error) {
+	if p.peekOperator("!") != "" {
+		p.pos++
+		result, err := p.parseUnary()
+		return !result, err
+	}
+
+	if p.peekOperator("(") != "" {
+		p.pos++
+		result, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if p.peekOperator(")") == "" {
+			return false, fmt.Errorf("missing closing parenthesis in condition")
+		}
+		p.pos++
+		return result, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return false, err
+	}
+
+	operator := p.peekOperator("==", "!=", "<=", ">=", "<", ">")
+	if operator == "" {
+		return isTruthy(left), nil
+	}
+	p.pos++
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return false, err
+	}
+	return compareConditionOperands(left, right, operator), nil
+}
+
+func (p *conditionParser) parseOperand() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("unexpected end of condition")
+	}
+
+	token := p.tokens[p.pos]
+	switch token.kind {
+	case tokenVariable:
+		p.pos++
+		value, err := resolvePlaceholder(token.text, p.variables)
+		if errors.Is(err, errUndefinedVariable) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("${%s}: %w", token.text, err)
+		}
+		return formatVariable(value), nil
+	case tokenOperand:
+		p.pos++
+		return token.text, nil
+	default:
+		return "", fmt.Errorf("unexpected %q in condition", token.text)
+	}
+}
+
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0", "no", "off":
+		return false
+	default:
+		return true
+	}
+}
+
+func compareConditionOperands(left, right, operator string) bool {
+	leftNumber, leftErr := strconv.ParseFloat(left, 64)
+	rightNumber, rightErr := strconv.ParseFloat(right, 64)
+	numeric := leftErr == nil && rightErr == nil
+
+	// Integers are compared exactly, since 64-bit IDs do not fit a float64
+	leftInt, leftIntErr := strconv.ParseInt(left, 10, 64)
+	rightInt, rightIntErr := strconv.ParseInt(right, 10, 64)
+
+	var cmp int
+	switch {
+	case leftIntErr == nil && rightIntErr == nil:
+		cmp = cmpInt64(leftInt, rightInt)
+	case numeric && leftNumber < rightNumber:
+		cmp = -1
+	case numeric && leftNumber > rightNumber:
+		cmp = 1
+	case numeric:
+		cmp = 0
+	default:
+		cmp = strings.Compare(left, right)
+	}
+
+	switch operator {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+func cmpInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
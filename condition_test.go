@@ -0,0 +1,96 @@
+package goresttest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	variables := map[string]interface{}{
+		"feature_flag": "false",
+		"env":          "staging",
+		"count":        "10",
+		"region":       "us east",
+		"id":           json.Number("12345678901234567"),
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+		wantError  bool
+	}{
+		{expression: "${feature_flag}", want: false},
+		{expression: "!${feature_flag}", want: true},
+		{expression: "${undefined}", want: false},
+		{expression: "${env} == staging", want: true},
+		{expression: "${env} != 'staging'", want: false},
+		{expression: `${region} == "us east"`, want: true},
+		{expression: "${count} > 9", want: true},
+		{expression: "${count} <= 9", want: false},
+		{expression: "${env} == prod || ${count} >= 10", want: true},
+		{expression: "${env} == staging && ${feature_flag}", want: false},
+		{expression: "!(${env} == prod || ${feature_flag})", want: true},
+		{expression: "${id} == 12345678901234567", want: true},
+		{expression: "${id} == 12345678901234568", want: false},
+		{expression: "${id} < 12345678901234568", want: true},
+		{expression: "true", want: true},
+		{expression: "(${env} == staging", wantError: true},
+		{expression: "${env} ==", wantError: true},
+		{expression: "${env", wantError: true},
+		{expression: "${random_int(a,b)} > 0", wantError: true},
+		{expression: "${missing:-${undefined}} == x", wantError: true},
+		{expression: "${missing:-x} == x", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, err := evaluateCondition(tt.expression, variables)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("evaluateCondition() error = %v, wantError %v", err, tt.wantError)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("evaluateCondition() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestExecutor_ConditionalExecution(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		Variables: map[string]interface{}{"env": "prod"},
+		Tests: []Test{
+			{Name: "Feature", URL: "/feature", Assertions: statusOK(), Extract: map[string]string{"feature": "json:path"}},
+			{Name: "Destructive", URL: "/destroy", SkipIf: "${env} == prod"},
+			{Name: "Feature Enabled", URL: "/enabled", RunIf: "${feature} == /feature", DependsOn: []string{"Feature"}},
+			{Name: "Needs Flag", URL: "/flag", RunIf: "${flag}"},
+			{Name: "Broken", URL: "/broken", SkipIf: "${random_int(a,b)} > 0"},
+		},
+	}
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []TestStatus{StatusPassed, StatusSkipped, StatusPassed, StatusSkipped, StatusFailed}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("Expected %s to be %s, got %s (%s)", result.Name, want[i], result.Status, result.Error)
+		}
+	}
+	if results[1].SkipReason == "" {
+		t.Errorf("Expected a skip reason for %s", results[1].Name)
+	}
+	if !strings.Contains(results[4].Error, "invalid skip_if condition") {
+		t.Errorf("Expected an invalid skip_if condition error, got %q", results[4].Error)
+	}
+
+	for _, path := range order() {
+		if path == "/destroy" || path == "/flag" || path == "/broken" {
+			t.Errorf("Expected %s not to be requested", path)
+		}
+	}
+}
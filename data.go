@@ -0,0 +1,216 @@
+package goresttest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DataSource provides the rows of a data-driven test. In YAML it is either an
+// inline list of variable maps or the path of a CSV, JSON or YAML data file.
+type DataSource struct {
+	Rows []map[string]string
+	File string
+}
+
+// UnmarshalYAML accepts either a list of variable maps or a file path
+func (d *DataSource) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		return value.Decode(&d.File)
+	case yaml.SequenceNode:
+		return value.Decode(&d.Rows)
+	default:
+		return fmt.Errorf("line %d: data must be a list of variable maps or a file path", value.Line)
+	}
+}
+
+// loadRows returns the rows of the data source. When a suite is parsed from
+// a file, relative data file paths have already been resolved against the
+// directory of that file.
+func (d *DataSource) loadRows() ([]map[string]string, error) {
+	if d.File == "" {
+		return d.Rows, nil
+	}
+
+	path := d.File
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file '%s': %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseCSVRows(content, path)
+	case ".json":
+		// Numbers are kept as written, so that large IDs are not formatted
+		// as floats
+		var records []map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err := decoder.Decode(&records); err != nil {
+			return nil, fmt.Errorf("failed to parse data file '%s': %w", path, err)
+		}
+		return stringifyRows(records), nil
+	case ".yaml", ".yml":
+		var records []map[string]interface{}
+		if err := yaml.Unmarshal(content, &records); err != nil {
+			return nil, fmt.Errorf("failed to parse data file '%s': %w", path, err)
+		}
+		return stringifyRows(records), nil
+	default:
+		return nil, fmt.Errorf("unsupported data file format: %s", path)
+	}
+}
+
+func parseCSVRows(content []byte, path string) ([]map[string]string, error) {
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data file '%s': %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[strings.TrimSpace(name)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// stringifyRows converts the records of a JSON or YAML data file to rows.
+// Values are formatted like variables, so lists and objects become JSON.
+func stringifyRows(records []map[string]interface{}) []map[string]string {
+	rows := make([]map[string]string, len(records))
+	for i, record := range records {
+		rows[i] = make(map[string]string, len(record))
+		for k, v := range record {
+			rows[i][k] = formatVariable(v)
+		}
+	}
+	return rows
+}
+
+// expandDataDrivenTests replaces every test with a matrix or data section by
+// one instance per row, which runs with the row variables.
+// depends_on entries naming an expanded test are replaced by all of its
+// instances.
+func expandDataDrivenTests(tests []Test) ([]Test, error) {
+	var expanded []Test
+	instances := make(map[string][]string)
+
+	for _, test := range tests {
+		if test.Data == nil && len(test.Matrix) == 0 {
+			expanded = append(expanded, test)
+			continue
+		}
+
+		rows, err := dataRows(test)
+		if err != nil {
+			return nil, fmt.Errorf("test %s: %w", describeTest(test), err)
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("test %s: data and matrix produce no test instances", describeTest(test))
+		}
+
+		for _, row := range rows {
+			instance := instantiateTest(test, row)
+			instances[test.Name] = append(instances[test.Name], instance.Name)
+			expanded = append(expanded, instance)
+		}
+	}
+
+	if len(instances) == 0 {
+		return expanded, nil
+	}
+
+	for i := range expanded {
+		var dependsOn []string
+		for _, depName := range expanded[i].DependsOn {
+			if names, exists := instances[depName]; exists {
+				dependsOn = append(dependsOn, names...)
+			} else {
+				dependsOn = append(dependsOn, depName)
+			}
+		}
+		expanded[i].DependsOn = dependsOn
+	}
+
+	return expanded, nil
+}
+
+// dataRows combines the data rows of a test with every combination of its
+// matrix values
+func dataRows(test Test) ([]map[string]string, error) {
+	rows := []map[string]string{{}}
+	if test.Data != nil {
+		var err error
+		rows, err = test.Data.loadRows()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]string, 0, len(test.Matrix))
+	for key := range test.Matrix {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var combined []map[string]string
+		for _, row := range rows {
+			for _, value := range test.Matrix[key] {
+				next := make(map[string]string, len(row)+1)
+				for k, v := range row {
+					next[k] = v
+				}
+				next[key] = value
+				combined = append(combined, next)
+			}
+		}
+		rows = combined
+	}
+
+	return rows, nil
+}
+
+// instantiateTest returns a copy of test that runs with the row variables.
+// The instance is named after the row unless the test name already uses one
+// of its variables.
+func instantiateTest(test Test, row map[string]string) Test {
+	instance := test
+	instance.Matrix = nil
+	instance.Data = nil
+	instance.variables = stringVariables(row)
+
+	instance.Name = interpolateRowVariables(test.Name, row)
+	if instance.Name == test.Name {
+		keys := make([]string, 0, len(row))
+		for key := range row {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = fmt.Sprintf("%s=%s", key, row[key])
+		}
+		instance.Name = fmt.Sprintf("%s [%s]", test.Name, strings.Join(pairs, ", "))
+	}
+
+	return instance
+}
@@ -0,0 +1,286 @@
+package goresttest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTestSuiteFromString_InlineData(t *testing.T) {
+	yamlContent := `
+name: "Users"
+tests:
+  - name: "Get User ${id}"
+    url: "/users/${id}"
+    data:
+      - id: 1
+        name: "Leanne Graham"
+      - id: 2
+        name: "Ervin Howell"
+    assertions:
+      - type: "json_path"
+        path: "id"
+        expected: "${id}"
+      - type: "json_path"
+        path: "name"
+        expected: "${name}"
+  - name: "Summary"
+    url: "/summary"
+    depends_on: ["Get User ${id}"]
+`
+
+	suite, err := ParseTestSuiteFromString(yamlContent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(suite.Tests) != 3 {
+		t.Fatalf("Expected 3 tests, got %d", len(suite.Tests))
+	}
+
+	first := suite.Tests[0]
+	if first.Name != "Get User 1" || InterpolateVariables(first.URL, first.variables) != "/users/1" {
+		t.Errorf("Unexpected first instance: %q %q", first.Name, first.URL)
+	}
+	engine := NewAssertionEngine()
+	if got := engine.interpolateExpectedValue(first.Assertions[0].Expected, first.variables); got != 1 {
+		t.Errorf("Expected numeric assertion value 1, got %#v", got)
+	}
+	if got := engine.interpolateExpectedValue(first.Assertions[1].Expected, first.variables); got != "Leanne Graham" {
+		t.Errorf("Expected name assertion value, got %#v", got)
+	}
+
+	wantDeps := []string{"Get User 1", "Get User 2"}
+	if !reflect.DeepEqual(suite.Tests[2].DependsOn, wantDeps) {
+		t.Errorf("Expected depends_on %v, got %v", wantDeps, suite.Tests[2].DependsOn)
+	}
+}
+
+func TestParseTestSuiteFromString_Matrix(t *testing.T) {
+	yamlContent := `
+name: "Matrix"
+tests:
+  - name: "List"
+    url: "/${resource}?page=${page}"
+    matrix:
+      resource: ["posts", "users"]
+      page: [1, 2]
+`
+
+	suite, err := ParseTestSuiteFromString(yamlContent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names, urls []string
+	for _, test := range suite.Tests {
+		names = append(names, test.Name)
+		urls = append(urls, InterpolateVariables(test.URL, test.variables))
+	}
+
+	wantNames := []string{
+		"List [page=1, resource=posts]",
+		"List [page=1, resource=users]",
+		"List [page=2, resource=posts]",
+		"List [page=2, resource=users]",
+	}
+	wantURLs := []string{"/posts?page=1", "/users?page=1", "/posts?page=2", "/users?page=2"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("Expected names %v, got %v", wantNames, names)
+	}
+	if !reflect.DeepEqual(urls, wantURLs) {
+		t.Errorf("Expected URLs %v, got %v", wantURLs, urls)
+	}
+}
+
+func TestParseTestSuite_DataFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"users.csv":  "id,name\n1,Leanne\n2,Ervin\n12345678901234567,Clementine\n",
+		"users.json": `[{"id": 1, "name": "Leanne"}, {"id": 2, "name": "Ervin"}, {"id": 12345678901234567, "name": "Clementine"}]`,
+		"users.yaml": "- id: 1\n  name: Leanne\n- id: 2\n  name: Ervin\n- id: 12345678901234567\n  name: Clementine\n",
+	}
+
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write data file: %v", err)
+		}
+
+		suiteFile := filepath.Join(dir, "suite.yml")
+		suiteContent := `
+name: "Data File"
+tests:
+  - name: "Get ${name}"
+    url: "/users/${id}"
+    data: "` + file + `"
+`
+		if err := os.WriteFile(suiteFile, []byte(suiteContent), 0644); err != nil {
+			t.Fatalf("Failed to write suite file: %v", err)
+		}
+
+		suite, err := ParseTestSuite(suiteFile)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", file, err)
+		}
+
+		if len(suite.Tests) != 3 || suite.Tests[1].Name != "Get Ervin" || InterpolateVariables(suite.Tests[1].URL, suite.Tests[1].variables) != "/users/2" {
+			t.Fatalf("%s: unexpected tests: %+v", file, suite.Tests)
+		}
+		if got := InterpolateVariables(suite.Tests[2].URL, suite.Tests[2].variables); got != "/users/12345678901234567" {
+			t.Errorf("%s: expected large IDs to be kept as written, got %s", file, got)
+		}
+	}
+}
+
+func TestParseTestSuiteFromString_DataDrivenDefaultsAndEscapes(t *testing.T) {
+	yamlContent := `
+name: "Users"
+tests:
+  - name: "Get ${id}"
+    url: "/users/${id}?page=${page:-1}&sort=${sort:-${id}}"
+    body: '{"template": "$${id}"}'
+    data:
+      - id: 7
+`
+
+	suite, err := ParseTestSuiteFromString(yamlContent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	test := suite.Tests[0]
+	if want, got := "/users/7?page=1&sort=7", InterpolateVariables(test.URL, test.variables); got != want {
+		t.Errorf("Expected URL %s, got %s", want, got)
+	}
+	if got := InterpolateVariables(test.Body, test.variables); got != `{"template": "${id}"}` {
+		t.Errorf("Expected escape to be kept until the request is sent, got %s", got)
+	}
+}
+
+func TestTestRunner_RunTestSuite_DataDrivenRuntimeVariables(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": {"1": "one", "3": "three"}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "body.json"), []byte(`{"id": "${id}"}`), 0644); err != nil {
+		t.Fatalf("Failed to write body file: %v", err)
+	}
+	suiteFile := filepath.Join(dir, "suite.yaml")
+	content := `
+name: "Rows"
+tests:
+  - name: "Create ${id}"
+    method: "POST"
+    url: "/items"
+    body_file: "` + filepath.Join(dir, "body.json") + `"
+    skip_if: "${id} == 2"
+    extract:
+      label: "json:items.${id}"
+    data:
+      - id: "1"
+      - id: "2"
+      - id: "3"
+  - name: "Tag"
+    url: "/tags"
+    for_each:
+      in: "${tags}"
+      as: "tag"
+    data:
+      - tags: '["x", "y"]'
+`
+	if err := os.WriteFile(suiteFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write suite: %v", err)
+	}
+
+	suite, err := ParseTestSuite(suiteFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	results, err := NewTestRunner(server.URL).RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	statuses := make(map[string]TestStatus)
+	for _, result := range results {
+		statuses[result.Name] = result.Status
+		if result.Status == StatusFailed {
+			t.Errorf("%s failed: %s", result.Name, result.Error)
+		}
+	}
+	if statuses["Create 2"] != StatusSkipped {
+		t.Errorf("Expected skip_if to skip the row with id 2, got %v", statuses)
+	}
+	if want := []string{`{"id": "1"}`, `{"id": "3"}`}; !reflect.DeepEqual(bodies[:2], want) {
+		t.Errorf("Expected bodies %v, got %v", want, bodies)
+	}
+	if results[2].Variables["label"] != "three" {
+		t.Errorf("Expected the extraction path to use the row variable, got %v", results[2].Variables)
+	}
+	if len(results) != 5 || !strings.HasSuffix(results[4].Name, "] [1]") {
+		t.Errorf("Expected one for_each iteration per element of the row list, got %d results", len(results))
+	}
+}
+
+func TestTestRunner_RunTestSuite_NestedDataFileValues(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"rows.json": `[{"tags": ["a", "b"], "meta": {"x": 1}}]`,
+		"rows.yaml": "- tags: [a, b]\n  meta: {x: 1}\n",
+	}
+
+	for file, content := range files {
+		bodies = nil
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write data file: %v", err)
+		}
+		suiteFile := filepath.Join(dir, "suite.yaml")
+		suiteContent := `
+name: "Nested"
+tests:
+  - name: "Create"
+    method: "POST"
+    url: "/items"
+    headers:
+      Content-Type: "application/json"
+    body: '{"tags": ${tags}, "meta": ${meta}}'
+    data: "` + file + `"
+`
+		if err := os.WriteFile(suiteFile, []byte(suiteContent), 0644); err != nil {
+			t.Fatalf("Failed to write suite file: %v", err)
+		}
+
+		suite, err := ParseTestSuite(suiteFile)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", file, err)
+		}
+		if want := `Create [meta={"x":1}, tags=["a","b"]]`; suite.Tests[0].Name != want {
+			t.Errorf("%s: expected name %s, got %s", file, want, suite.Tests[0].Name)
+		}
+
+		if _, err := NewTestRunner(server.URL).RunTestSuite(suite); err != nil {
+			t.Fatalf("%s: unexpected error: %v", file, err)
+		}
+		if want := `{"tags": ["a","b"], "meta": {"x":1}}`; len(bodies) != 1 || bodies[0] != want {
+			t.Errorf("%s: expected body %s, got %v", file, want, bodies)
+		}
+	}
+}
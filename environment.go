@@ -0,0 +1,47 @@
+package goresttest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ApplyEnvironment selects the named environment of the suite. Its base URL
+// replaces the suite's, its variables override the suite's, and its headers
+// are added to every test that does not set them. The suite's variable and
+// header maps are replaced rather than modified, so a shallow copy of a
+// suite can select an environment without affecting the original.
+func (s *TestSuite) ApplyEnvironment(name string) error {
+	env, exists := s.Environments[name]
+	if !exists {
+		return fmt.Errorf("unknown environment %q (available: %s)", name, strings.Join(s.environmentNames(), ", "))
+	}
+
+	if env.BaseURL != "" {
+		s.BaseURL = env.BaseURL
+	}
+	s.Variables = mergeVariables(s.Variables, env.Variables)
+
+	if len(env.Headers) > 0 {
+		for _, tests := range []*[]Test{&s.Setup, &s.Tests, &s.Teardown} {
+			withHeaders := make([]Test, len(*tests))
+			for i, test := range *tests {
+				test.Headers = mergeStringMaps(env.Headers, test.Headers)
+				withHeaders[i] = test
+			}
+			*tests = withHeaders
+		}
+	}
+
+	s.Environment = name
+	return nil
+}
+
+func (s *TestSuite) environmentNames() []string {
+	names := make([]string, 0, len(s.Environments))
+	for name := range s.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
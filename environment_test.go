@@ -0,0 +1,107 @@
+package goresttest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTestSuite_ApplyEnvironment(t *testing.T) {
+	suite, err := ParseTestSuiteFromString(`
+name: "Environments"
+base_url: "http://localhost:8080"
+variables:
+  user: "admin"
+  region: "local"
+environments:
+  staging:
+    base_url: "https://staging.example.com"
+    variables:
+      region: "eu"
+    headers:
+      X-Env: "staging"
+      Accept: "text/plain"
+tests:
+  - name: "Get"
+    url: "/"
+    headers:
+      Accept: "application/json"
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := suite.ApplyEnvironment("staging"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if suite.BaseURL != "https://staging.example.com" {
+		t.Errorf("Expected environment base URL, got %q", suite.BaseURL)
+	}
+	if suite.Variables["region"] != "eu" || suite.Variables["user"] != "admin" {
+		t.Errorf("Expected environment variables merged over suite variables, got %v", suite.Variables)
+	}
+	headers := suite.Tests[0].Headers
+	if headers["X-Env"] != "staging" || headers["Accept"] != "application/json" {
+		t.Errorf("Expected environment headers merged under test headers, got %v", headers)
+	}
+	if suite.Environment != "staging" {
+		t.Errorf("Expected environment staging, got %q", suite.Environment)
+	}
+
+	err = suite.ApplyEnvironment("prod")
+	if err == nil || !strings.Contains(err.Error(), `unknown environment "prod" (available: staging)`) {
+		t.Errorf("Expected unknown environment error, got %v", err)
+	}
+}
+
+func TestTestRunner_RunTestSuite_WithEnvironment(t *testing.T) {
+	var envHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		envHeader = r.Header.Get("X-Env")
+		w.Write([]byte(`{"region": "` + r.URL.Query().Get("region") + `"}`))
+	}))
+	defer server.Close()
+
+	suite := &TestSuite{
+		BaseURL:   "http://127.0.0.1:1",
+		Variables: map[string]interface{}{"region": "local"},
+		Environments: map[string]Environment{
+			"staging": {
+				BaseURL:   server.URL,
+				Variables: map[string]interface{}{"region": "eu"},
+				Headers:   map[string]string{"X-Env": "staging"},
+			},
+		},
+		Tests: []Test{{
+			Name:       "Get",
+			URL:        "/?region=${region}",
+			Assertions: []Assertion{{Type: "json_path", Path: "region", Expected: "eu"}},
+		}},
+	}
+
+	runner := NewTestRunner(suite.BaseURL)
+	results, err := runner.RunTestSuite(suite, WithEnvironment("staging"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 1 || results[0].Status != StatusPassed {
+		t.Fatalf("Expected test to pass against the staging server, got %+v", results[0])
+	}
+	if results[0].Environment != "staging" {
+		t.Errorf("Expected result environment staging, got %q", results[0].Environment)
+	}
+	if envHeader != "staging" {
+		t.Errorf("Expected X-Env header staging, got %q", envHeader)
+	}
+
+	if suite.BaseURL != "http://127.0.0.1:1" || suite.Variables["region"] != "local" || suite.Tests[0].Headers != nil || suite.Environment != "" {
+		t.Errorf("Expected the original suite to be unchanged, got %+v", suite)
+	}
+
+	if _, err := runner.RunTestSuite(suite, WithEnvironment("prod")); err == nil {
+		t.Error("Expected error for unknown environment, got nil")
+	}
+}
@@ -93,7 +93,7 @@ func main() {
 	} else {
 		fmt.Printf("Test %s: %s\n", createResult.Name, getTestStatus(createResult.Success))
 		if createResult.Success {
-			fmt.Printf("  Extracted post_id: %s\n", createResult.Variables["post_id"])
+			fmt.Printf("  Extracted post_id: %v\n", createResult.Variables["post_id"])
 
 			// Use extracted variable in next test
 			getTest := goresttest.Test{
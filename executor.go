@@ -1,17 +1,23 @@
 package goresttest
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 )
 
 type TestExecutor struct {
 	client            *HTTPClient
 	assertionEngine   *AssertionEngine
 	variableExtractor *VariableExtractor
-	globalVariables   map[string]string
+	globalVariables   map[string]interface{}
 	testResults       map[string]*TestResult
+	defaultTimeout    time.Duration
+	defaultAuth       *Auth
+	maxFailures       int
+	failures          int
 	mutex             sync.RWMutex
 }
 
@@ -20,113 +26,232 @@ func NewTestExecutor(baseURL string) *TestExecutor {
 		client:            NewHTTPClient(baseURL),
 		assertionEngine:   NewAssertionEngine(),
 		variableExtractor: NewVariableExtractor(),
-		globalVariables:   make(map[string]string),
+		globalVariables:   make(map[string]interface{}),
 		testResults:       make(map[string]*TestResult),
 	}
 }
 
 func (te *TestExecutor) ExecuteTestSuite(suite *TestSuite) ([]*TestResult, error) {
-	te.globalVariables = suite.Variables
-	if te.globalVariables == nil {
-		te.globalVariables = make(map[string]string)
+	return te.ExecuteTestSuiteContext(context.Background(), suite)
+}
+
+// ExecuteTestSuiteContext executes a test suite, stopping when ctx is done.
+// Once ctx is cancelled no new tests are started, in-flight requests are
+// aborted and every unfinished test is reported as cancelled. Teardown tests
+// run regardless.
+func (te *TestExecutor) ExecuteTestSuiteContext(ctx context.Context, suite *TestSuite) ([]*TestResult, error) {
+	te.globalVariables = make(map[string]interface{}, len(suite.Variables))
+	for k, v := range suite.Variables {
+		// Suite variables may be read from the environment, e.g.
+		// token: "${env:API_TOKEN}"
+		if s, ok := v.(string); ok {
+			v = InterpolateVariables(s, nil)
+		}
+		te.globalVariables[k] = v
+	}
+	te.defaultTimeout = suite.Timeout
+	te.defaultAuth = suite.Auth
+	te.maxFailures = suite.MaxFailures
+	if suite.FailFast {
+		te.maxFailures = 1
+	}
+	te.failures = 0
+	defer func(strict bool) { te.client.strictVariables = strict }(te.client.strictVariables)
+	te.client.strictVariables = suite.StrictVariables
+	defer func(cookies bool) { te.client.cookies = cookies }(te.client.cookies)
+	te.client.cookies = suite.Cookies
+	te.client.ResetSessions()
+	te.client.ResetTokens()
+	if suite.TLS != nil {
+		config := suite.TLS.interpolate(func(text string) string {
+			return InterpolateVariables(text, te.globalVariables)
+		})
+		if err := te.client.SetTLSConfig(config); err != nil {
+			return nil, fmt.Errorf("invalid tls settings: %w", err)
+		}
+		defer te.client.SetTLSConfig(nil)
+	}
+	if env, selected := suite.Environments[suite.Environment]; selected && env.BaseURL != "" {
+		defer func(baseURL string) { te.client.baseURL = baseURL }(te.client.baseURL)
+		te.client.baseURL = env.BaseURL
+	}
+
+	if err := ValidateSuite(suite); err != nil {
+		return nil, err
+	}
+
+	setupResults := te.executeSetup(ctx, suite.Setup)
+	setPhase(setupResults, PhaseSetup)
+	results := setupResults
+
+	var testResults []*TestResult
+	if failed := firstUnsuccessful(setupResults); failed != nil {
+		// Tests that did not run because the suite was cancelled during setup
+		// are cancelled, not skipped
+		cancelled := ctx.Err()
+		if cancelled == nil && failed.Status == StatusCancelled {
+			cancelled = context.Canceled
+		}
+		for _, test := range suite.Tests {
+			if cancelled != nil {
+				testResults = append(testResults, cancelledResult(test, cancelled))
+				continue
+			}
+			testResults = append(testResults, &TestResult{
+				Name:             test.Name,
+				Status:           StatusSkipped,
+				SkipReason:       fmt.Sprintf("setup test %q did not pass", failed.Name),
+				FailedDependency: failed.Name,
+			})
+		}
+	} else {
+		if suite.Parallel {
+			testResults, _ = te.executeParallel(ctx, suite.Tests, suite.MaxWorkers)
+		} else {
+			testResults, _ = te.executeSequential(ctx, suite.Tests)
+		}
+	}
+	results = append(results, testResults...)
+
+	if len(suite.Teardown) > 0 {
+		// Teardown cleans up after the tests, so it sees every extracted
+		// variable and runs even if the tests failed or ctx was cancelled.
+		te.mergeGlobalVariables(testResults)
+		te.mutex.Lock()
+		te.maxFailures = 0
+		te.mutex.Unlock()
+
+		teardownResults, _ := te.executeSequential(context.WithoutCancel(ctx), suite.Teardown)
+		setPhase(teardownResults, PhaseTeardown)
+		results = append(results, teardownResults...)
 	}
 
-	if suite.Parallel {
-		return te.executeParallel(suite.Tests, suite.MaxWorkers)
+	for _, result := range results {
+		result.Environment = suite.Environment
 	}
 
-	return te.executeSequential(suite.Tests)
+	return results, nil
 }
 
-func (te *TestExecutor) executeSequential(tests []Test) ([]*TestResult, error) {
+// executeSetup runs the setup tests in order. The variables extracted by
+// each passing setup test become global variables right away, so later setup
+// tests can use them without depending on it.
+func (te *TestExecutor) executeSetup(ctx context.Context, tests []Test) []*TestResult {
 	var results []*TestResult
 
-	for _, test := range tests {
-		if !te.canExecuteTest(test) {
+	for _, index := range newDependencyGraph(tests).order() {
+		count := len(results)
+		results = appendResult(results, te.runTest(ctx, tests[index]))
+		te.mergeGlobalVariables(results[count:])
+	}
+
+	return results
+}
+
+// mergeGlobalVariables makes the variables extracted by passed tests
+// available to every test that runs afterwards.
+func (te *TestExecutor) mergeGlobalVariables(results []*TestResult) {
+	te.mutex.Lock()
+	defer te.mutex.Unlock()
+
+	for _, result := range results {
+		if resultStatus(result) != StatusPassed {
 			continue
 		}
+		for k, v := range result.Variables {
+			te.globalVariables[k] = v
+		}
+	}
+}
 
-		result, err := te.executeTest(test)
-		if err != nil {
-			result.Success = false
-			result.Error = err.Error()
+// appendResult appends the result of a test to results, or the results of
+// its iterations for a for_each test
+func appendResult(results []*TestResult, result *TestResult) []*TestResult {
+	if len(result.Iterations) > 0 {
+		return append(results, result.Iterations...)
+	}
+	return append(results, result)
+}
+
+// firstUnsuccessful returns the first result that did not pass, or nil
+func firstUnsuccessful(results []*TestResult) *TestResult {
+	for _, result := range results {
+		if resultStatus(result) != StatusPassed {
+			return result
 		}
+	}
+	return nil
+}
 
-		te.mutex.Lock()
-		te.testResults[test.Name] = result
-		te.mutex.Unlock()
+func setPhase(results []*TestResult, phase string) {
+	for _, result := range results {
+		result.Phase = phase
+	}
+}
+
+func (te *TestExecutor) executeSequential(ctx context.Context, tests []Test) ([]*TestResult, error) {
+	var results []*TestResult
 
-		results = append(results, result)
+	for _, index := range newDependencyGraph(tests).order() {
+		results = appendResult(results, te.runTest(ctx, tests[index]))
 	}
 
 	return results, nil
 }
 
-func (te *TestExecutor) executeParallel(tests []Test, maxWorkers int) ([]*TestResult, error) {
+// executeParallel schedules tests on a pool of maxWorkers goroutines. A test
+// is released to the pool as soon as every test it depends on has finished,
+// so dependency chains of any depth run in the correct order while unrelated
+// tests keep the workers busy.
+func (te *TestExecutor) executeParallel(ctx context.Context, tests []Test, maxWorkers int) ([]*TestResult, error) {
 	if maxWorkers <= 0 {
 		maxWorkers = 10
 	}
 
-	testChan := make(chan Test, len(tests))
-	resultChan := make(chan *TestResult, len(tests))
+	graph := newDependencyGraph(tests)
+	pending := graph.pendingCounts()
 
-	var wg sync.WaitGroup
+	readyChan := make(chan int, len(tests))
+	doneChan := make(chan scheduledResult, len(tests))
 
+	var wg sync.WaitGroup
 	for i := 0; i < maxWorkers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for test := range testChan {
-				if te.canExecuteTest(test) {
-					result, err := te.executeTest(test)
-					if err != nil {
-						result.Success = false
-						result.Error = err.Error()
-					}
-
-					te.mutex.Lock()
-					te.testResults[test.Name] = result
-					te.mutex.Unlock()
-
-					resultChan <- result
-				}
+			for index := range readyChan {
+				doneChan <- scheduledResult{index: index, result: te.runTest(ctx, tests[index])}
 			}
 		}()
 	}
 
-	independentTests := te.getIndependentTests(tests)
-	for _, test := range independentTests {
-		testChan <- test
+	inFlight := 0
+	for index, count := range pending {
+		if count == 0 {
+			readyChan <- index
+			inFlight++
+		}
 	}
-	close(testChan)
-
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
 
 	var results []*TestResult
-	for result := range resultChan {
-		results = append(results, result)
-	}
+	for inFlight > 0 {
+		done := <-doneChan
+		inFlight--
 
-	dependentTests := te.getDependentTests(tests)
-	for _, test := range dependentTests {
-		if te.canExecuteTest(test) {
-			result, err := te.executeTest(test)
-			if err != nil {
-				result.Success = false
-				result.Error = err.Error()
-			}
-
-			te.mutex.Lock()
-			te.testResults[test.Name] = result
-			te.mutex.Unlock()
+		results = appendResult(results, done.result)
 
-			results = append(results, result)
+		for _, dependent := range graph.dependents[done.index] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				readyChan <- dependent
+				inFlight++
+			}
 		}
 	}
 
+	close(readyChan)
+	wg.Wait()
+
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Name < results[j].Name
 	})
@@ -134,8 +259,56 @@ func (te *TestExecutor) executeParallel(tests []Test, maxWorkers int) ([]*TestRe
 	return results, nil
 }
 
-func (te *TestExecutor) executeTest(test Test) (*TestResult, error) {
-	currentVariables := make(map[string]string)
+// runTest executes a single test, or skips it if one of its dependencies did
+// not pass, and records its result so that dependent tests can see it. Tests
+// started after ctx is done, or aborted by it, are reported as cancelled, and
+// tests started after the failure limit was reached are reported as not run.
+func (te *TestExecutor) runTest(ctx context.Context, test Test) *TestResult {
+	var result *TestResult
+	if ctx.Err() != nil {
+		result = cancelledResult(test, ctx.Err())
+	} else if te.failureLimitReached() {
+		result = &TestResult{
+			Name:       test.Name,
+			Status:     StatusNotRun,
+			SkipReason: fmt.Sprintf("suite stopped after %d failed test(s)", te.maxFailures),
+		}
+	} else if skipped := te.skipIfDependencyFailed(test); skipped != nil {
+		result = skipped
+	} else {
+		var err error
+		result, err = te.executeTest(ctx, test)
+		if err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+		result.Status = resultStatus(result)
+		if err != nil && ctx.Err() != nil {
+			result.Status = StatusCancelled
+		}
+	}
+
+	te.mutex.Lock()
+	te.testResults[test.Name] = result
+	if result.Status == StatusFailed {
+		te.failures++
+	}
+	te.mutex.Unlock()
+
+	return result
+}
+
+// failureLimitReached reports whether enough tests have failed that no more
+// tests should be started
+func (te *TestExecutor) failureLimitReached() bool {
+	te.mutex.RLock()
+	defer te.mutex.RUnlock()
+
+	return te.maxFailures > 0 && te.failures >= te.maxFailures
+}
+
+func (te *TestExecutor) executeTest(ctx context.Context, test Test) (*TestResult, error) {
+	currentVariables := make(map[string]interface{})
 	te.mutex.RLock()
 	for k, v := range te.globalVariables {
 		currentVariables[k] = v
@@ -150,13 +323,66 @@ func (te *TestExecutor) executeTest(test Test) (*TestResult, error) {
 	}
 	te.mutex.RUnlock()
 
-	result, err := te.client.ExecuteRequest(test, currentVariables)
+	// Data-driven instances run with the variables of their row
+	for k, v := range test.variables {
+		currentVariables[k] = v
+	}
+
+	if test.Timeout <= 0 {
+		test.Timeout = te.defaultTimeout
+	}
+	if test.Auth == nil {
+		test.Auth = te.defaultAuth
+	}
+
+	skipped, err := conditionalSkip(test, currentVariables)
+	if err != nil {
+		return &TestResult{Name: test.Name, Success: false, Error: err.Error()}, err
+	}
+	if skipped != nil {
+		return skipped, nil
+	}
+
+	if test.ForEach != nil {
+		return te.executeForEach(ctx, test, currentVariables)
+	}
+
+	return te.executeWithRetry(ctx, test, currentVariables)
+}
+
+// executeWithRetry runs a test once, or as often as its retry policy allows.
+func (te *TestExecutor) executeWithRetry(ctx context.Context, test Test, currentVariables map[string]interface{}) (*TestResult, error) {
+	if test.Retry == nil {
+		return te.attemptTest(ctx, test, currentVariables)
+	}
+
+	var attempts []Attempt
+	for number := 1; ; number++ {
+		result, err := te.attemptTest(ctx, test, currentVariables)
+		attempts = append(attempts, newAttempt(number, result))
+
+		if number >= test.Retry.attempts() || !test.Retry.shouldRetry(result, err) || !sleepContext(ctx, test.Retry.delay(number)) {
+			result.Attempts = attempts
+			return result, err
+		}
+	}
+}
+
+// attemptTest sends the request for a test once and runs its extractions and
+// assertions against the response.
+func (te *TestExecutor) attemptTest(ctx context.Context, test Test, currentVariables map[string]interface{}) (*TestResult, error) {
+	result, err := te.client.ExecuteRequestContext(ctx, test, currentVariables)
 	if err != nil {
 		return result, err
 	}
 
 	if len(test.Extract) > 0 {
-		if err := te.variableExtractor.ExtractVariables(result, test.Extract); err != nil {
+		// Extraction paths may use variables, e.g. json:items.${index}.id
+		extract := make(map[string]string, len(test.Extract))
+		for name, expression := range test.Extract {
+			extract[name] = InterpolateVariables(expression, currentVariables)
+		}
+		if err := te.variableExtractor.ExtractVariables(result, extract); err != nil {
 			result.Success = false
 			result.Error = fmt.Sprintf("variable extraction failed: %v", err)
 			return result, err
@@ -174,39 +400,55 @@ func (te *TestExecutor) executeTest(test Test) (*TestResult, error) {
 	return result, nil
 }
 
-func (te *TestExecutor) canExecuteTest(test Test) bool {
+// skipIfDependencyFailed returns a skipped result for test if any of its
+// dependencies did not pass, or nil if the test can be executed. The result
+// names the upstream test that actually failed, following chains of skipped
+// tests back to their origin.
+func (te *TestExecutor) skipIfDependencyFailed(test Test) *TestResult {
 	te.mutex.RLock()
 	defer te.mutex.RUnlock()
 
 	for _, depName := range test.DependsOn {
-		if depResult, exists := te.testResults[depName]; !exists || !depResult.Success {
-			return false
+		depResult, exists := te.testResults[depName]
+		if !exists {
+			return &TestResult{
+				Name:             test.Name,
+				Status:           StatusSkipped,
+				SkipReason:       fmt.Sprintf("dependency %q has not been run", depName),
+				FailedDependency: depName,
+			}
 		}
-	}
-	return true
-}
 
-func (te *TestExecutor) getIndependentTests(tests []Test) []Test {
-	var independent []Test
-	for _, test := range tests {
-		if len(test.DependsOn) == 0 {
-			independent = append(independent, test)
+		switch resultStatus(depResult) {
+		case StatusFailed:
+			return &TestResult{
+				Name:             test.Name,
+				Status:           StatusSkipped,
+				SkipReason:       fmt.Sprintf("dependency %q failed", depName),
+				FailedDependency: depName,
+			}
+		case StatusSkipped:
+			failedDependency := depResult.FailedDependency
+			if failedDependency == "" {
+				failedDependency = depName
+			}
+			return &TestResult{
+				Name:             test.Name,
+				Status:           StatusSkipped,
+				SkipReason:       fmt.Sprintf("dependency %q was skipped", depName),
+				FailedDependency: failedDependency,
+			}
 		}
 	}
-	return independent
+	return nil
 }
 
-func (te *TestExecutor) getDependentTests(tests []Test) []Test {
-	var dependent []Test
-	for _, test := range tests {
-		if len(test.DependsOn) > 0 {
-			dependent = append(dependent, test)
-		}
+// cancelledResult returns the result for a test that was not run, or was
+// aborted, because the suite was cancelled.
+func cancelledResult(test Test, err error) *TestResult {
+	return &TestResult{
+		Name:   test.Name,
+		Status: StatusCancelled,
+		Error:  fmt.Sprintf("test cancelled: %v", err),
 	}
-
-	sort.Slice(dependent, func(i, j int) bool {
-		return len(dependent[i].DependsOn) < len(dependent[j].DependsOn)
-	})
-
-	return dependent
 }
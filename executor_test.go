@@ -0,0 +1,379 @@
+package goresttest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer returns a server that records the order in which paths are
+// requested. Paths starting with /fail respond with 500 and paths starting
+// with /slow block until the client goes away.
+func recordingServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mutex sync.Mutex
+	var order []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		order = append(order, r.URL.Path)
+		mutex.Unlock()
+
+		if strings.HasPrefix(r.URL.Path, "/slow") {
+			<-r.Context().Done()
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/fail") {
+			w.WriteHeader(500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), order...)
+	}
+}
+
+func statusOK() []Assertion {
+	return []Assertion{{Type: "status_code", Expected: 200}}
+}
+
+func TestTestExecutor_ExecuteParallel_DeepChain(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		Parallel:   true,
+		MaxWorkers: 4,
+		Tests: []Test{
+			{Name: "C", URL: "/c", DependsOn: []string{"B"}, Assertions: statusOK()},
+			{Name: "B", URL: "/b", DependsOn: []string{"A"}, Assertions: statusOK()},
+			{Name: "A", URL: "/a", Assertions: statusOK()},
+			{Name: "D", URL: "/d", Assertions: statusOK()},
+			{Name: "E", URL: "/e", DependsOn: []string{"A", "D"}, Assertions: statusOK()},
+		},
+	}
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("Expected %s to succeed, error: %s", result.Name, result.Error)
+		}
+	}
+
+	position := make(map[string]int)
+	for i, path := range order() {
+		position[path] = i
+	}
+	before := [][2]string{{"/a", "/b"}, {"/b", "/c"}, {"/a", "/e"}, {"/d", "/e"}}
+	for _, pair := range before {
+		if position[pair[0]] > position[pair[1]] {
+			t.Errorf("Expected %s to run before %s, order: %v", pair[0], pair[1], order())
+		}
+	}
+}
+
+func TestTestExecutor_ExecuteParallel_FailedDependency(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		Parallel:   true,
+		MaxWorkers: 2,
+		Tests: []Test{
+			{Name: "Login", URL: "/fail/login", Assertions: statusOK()},
+			{Name: "Profile", URL: "/profile", DependsOn: []string{"Login"}, Assertions: statusOK()},
+			{Name: "Settings", URL: "/settings", DependsOn: []string{"Profile"}, Assertions: statusOK()},
+			{Name: "Health", URL: "/health", Assertions: statusOK()},
+		},
+	}
+
+	if _, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, path := range order() {
+		if path == "/profile" || path == "/settings" {
+			t.Errorf("Expected %s not to be requested after its dependency failed", path)
+		}
+	}
+}
+
+func TestTestExecutor_SkippedDependents(t *testing.T) {
+	server, _ := recordingServer(t)
+
+	for _, parallel := range []bool{false, true} {
+		suite := &TestSuite{
+			Parallel:   parallel,
+			MaxWorkers: 2,
+			Tests: []Test{
+				{Name: "Settings", URL: "/settings", DependsOn: []string{"Profile"}, Assertions: statusOK()},
+				{Name: "Profile", URL: "/profile", DependsOn: []string{"Login"}, Assertions: statusOK()},
+				{Name: "Login", URL: "/fail/login", Assertions: statusOK()},
+				{Name: "Health", URL: "/health", Assertions: statusOK()},
+			},
+		}
+
+		results, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(results) != 4 {
+			t.Fatalf("parallel=%t: expected 4 results, got %d", parallel, len(results))
+		}
+
+		byName := make(map[string]*TestResult)
+		for _, result := range results {
+			byName[result.Name] = result
+		}
+
+		want := map[string]TestStatus{
+			"Login":    StatusFailed,
+			"Profile":  StatusSkipped,
+			"Settings": StatusSkipped,
+			"Health":   StatusPassed,
+		}
+		for name, status := range want {
+			if byName[name].Status != status {
+				t.Errorf("parallel=%t: expected %s to be %s, got %s", parallel, name, status, byName[name].Status)
+			}
+		}
+
+		for _, name := range []string{"Profile", "Settings"} {
+			if byName[name].FailedDependency != "Login" {
+				t.Errorf("parallel=%t: expected %s to name Login as failed dependency, got %q", parallel, name, byName[name].FailedDependency)
+			}
+			if byName[name].SkipReason == "" {
+				t.Errorf("parallel=%t: expected %s to have a skip reason", parallel, name)
+			}
+		}
+	}
+}
+
+func TestTestExecutor_ExecuteTestSuiteContext_Cancel(t *testing.T) {
+	server, order := recordingServer(t)
+
+	for _, parallel := range []bool{false, true} {
+		suite := &TestSuite{
+			Parallel:   parallel,
+			MaxWorkers: 1,
+			Tests: []Test{
+				{Name: "Slow", URL: "/slow", Assertions: statusOK()},
+				{Name: "After Slow", URL: "/after", DependsOn: []string{"Slow"}, Assertions: statusOK()},
+				{Name: "Queued", URL: "/queued", Assertions: statusOK()},
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		results, err := NewTestExecutor(server.URL).ExecuteTestSuiteContext(ctx, suite)
+		cancel()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(results) != 3 {
+			t.Fatalf("parallel=%t: expected 3 results, got %d", parallel, len(results))
+		}
+		for _, result := range results {
+			if result.Status != StatusCancelled {
+				t.Errorf("parallel=%t: expected %s to be cancelled, got %s (%s)", parallel, result.Name, result.Status, result.Error)
+			}
+		}
+	}
+
+	for _, path := range order() {
+		if path != "/slow" {
+			t.Errorf("Expected no request after cancellation, got %s", path)
+		}
+	}
+}
+
+func TestTestExecutor_FailureLimits(t *testing.T) {
+	server, _ := recordingServer(t)
+
+	tests := []Test{
+		{Name: "1 Broken", URL: "/fail/one", Assertions: statusOK()},
+		{Name: "2 Health", URL: "/health", Assertions: statusOK()},
+		{Name: "3 Broken", URL: "/fail/three", Assertions: statusOK()},
+		{Name: "4 Profile", URL: "/profile", Assertions: statusOK()},
+	}
+
+	cases := []struct {
+		name  string
+		suite TestSuite
+		want  []TestStatus
+	}{
+		{
+			name:  "fail fast",
+			suite: TestSuite{FailFast: true},
+			want:  []TestStatus{StatusFailed, StatusNotRun, StatusNotRun, StatusNotRun},
+		},
+		{
+			name:  "max failures",
+			suite: TestSuite{MaxFailures: 2},
+			want:  []TestStatus{StatusFailed, StatusPassed, StatusFailed, StatusNotRun},
+		},
+		{
+			name:  "fail fast parallel",
+			suite: TestSuite{FailFast: true, Parallel: true, MaxWorkers: 1},
+			want:  []TestStatus{StatusFailed, StatusNotRun, StatusNotRun, StatusNotRun},
+		},
+		{
+			name:  "no limit",
+			suite: TestSuite{},
+			want:  []TestStatus{StatusFailed, StatusPassed, StatusFailed, StatusPassed},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			suite := tc.suite
+			suite.Tests = tests
+
+			results, err := NewTestExecutor(server.URL).ExecuteTestSuite(&suite)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(results) != len(tc.want) {
+				t.Fatalf("Expected %d results, got %d", len(tc.want), len(results))
+			}
+			for i, result := range results {
+				if result.Status != tc.want[i] {
+					t.Errorf("Expected %s to be %s, got %s", result.Name, tc.want[i], result.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestTestExecutor_SetupAndTeardown(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		Parallel: true,
+		Setup: []Test{
+			{Name: "Login", URL: "/login", Assertions: statusOK(), Extract: map[string]string{"session": "json:path"}},
+			{Name: "Token", URL: "/token${session}", Assertions: statusOK()},
+		},
+		Tests: []Test{
+			{Name: "Create Item", URL: "/items${session}", Assertions: statusOK(), Extract: map[string]string{"item": "json:path"}},
+			{Name: "Broken", URL: "/fail", Assertions: statusOK()},
+		},
+		Teardown: []Test{
+			{Name: "Delete Item", URL: "/delete${item}", Assertions: statusOK()},
+		},
+	}
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Later setup tests see the variables of earlier ones without depends_on
+	want := []string{"/login", "/token/login", "/items/login", "/fail", "/delete/items/login"}
+	got := order()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[4] != want[4] {
+		t.Errorf("Expected requests %v, got %v", want, got)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(results))
+	}
+	if results[1].Phase != PhaseSetup || results[4].Phase != PhaseTeardown || results[2].Phase != "" {
+		t.Errorf("Unexpected phases: %q, %q, %q", results[1].Phase, results[2].Phase, results[4].Phase)
+	}
+	if results[4].Status != StatusPassed {
+		t.Errorf("Expected teardown to pass, got %s: %s", results[4].Status, results[4].Error)
+	}
+}
+
+func TestTestExecutor_SetupFailure(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		Setup:    []Test{{Name: "Login", URL: "/fail/login", Assertions: statusOK()}},
+		Tests:    []Test{{Name: "Profile", URL: "/profile", Assertions: statusOK()}},
+		Teardown: []Test{{Name: "Logout", URL: "/logout", Assertions: statusOK()}},
+	}
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := order(); len(got) != 2 || got[1] != "/logout" {
+		t.Errorf("Expected only setup and teardown requests, got %v", got)
+	}
+	if results[1].Status != StatusSkipped || results[1].FailedDependency != "Login" {
+		t.Errorf("Expected Profile to be skipped because of Login, got %s (%q)", results[1].Status, results[1].FailedDependency)
+	}
+}
+
+func TestTestExecutor_CancelDuringSetup(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		Setup: []Test{{Name: "Login", URL: "/slow/login", Assertions: statusOK()}},
+		Tests: []Test{{Name: "Profile", URL: "/profile", Assertions: statusOK()}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuiteContext(ctx, suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 2 || results[0].Status != StatusCancelled {
+		t.Fatalf("Expected the setup test to be cancelled, got %v", results)
+	}
+	if results[1].Status != StatusCancelled || results[1].SkipReason != "" {
+		t.Errorf("Expected Profile to be cancelled, got %s (%s)", results[1].Status, results[1].SkipReason)
+	}
+	if got := order(); len(got) != 1 {
+		t.Errorf("Expected only the setup request, got %v", got)
+	}
+}
+
+func TestTestExecutor_TeardownAfterCancel(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		Tests:    []Test{{Name: "Slow", URL: "/slow", Assertions: statusOK()}},
+		Teardown: []Test{{Name: "Cleanup", URL: "/cleanup", Assertions: statusOK()}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuiteContext(ctx, suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != StatusCancelled {
+		t.Errorf("Expected Slow to be cancelled, got %s", results[0].Status)
+	}
+	if results[1].Status != StatusPassed {
+		t.Errorf("Expected teardown to run after cancellation, got %s: %s", results[1].Status, results[1].Error)
+	}
+	if got := order(); len(got) != 2 || got[1] != "/cleanup" {
+		t.Errorf("Expected teardown request after cancellation, got %v", got)
+	}
+}
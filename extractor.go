@@ -3,6 +3,8 @@ package goresttest
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
@@ -10,6 +12,10 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
+// extractorTypes lists the expression prefixes supported by the
+// VariableExtractor
+var extractorTypes = []string{"json", "header", "cookie", "regex", "css", "status", "response_time"}
+
 // VariableExtractor handles extraction of variables from test responses
 type VariableExtractor struct{}
 
@@ -21,7 +27,7 @@ func NewVariableExtractor() *VariableExtractor {
 // ExtractVariables extracts variables from a test result using the provided extraction rules
 func (ve *VariableExtractor) ExtractVariables(result *TestResult, extractions map[string]string) error {
 	if result.Variables == nil {
-		result.Variables = make(map[string]string)
+		result.Variables = make(map[string]interface{})
 	}
 	
 	for varName, expression := range extractions {
@@ -35,10 +41,14 @@ func (ve *VariableExtractor) ExtractVariables(result *TestResult, extractions ma
 	return nil
 }
 
-func (ve *VariableExtractor) extractValue(result *TestResult, expression string) (string, error) {
+// extractValue evaluates an extraction expression. JSON values keep their
+// type, with numbers as json.Number so that large IDs keep their precision,
+// status codes are integers and response times are milliseconds as float64;
+// everything else is a string.
+func (ve *VariableExtractor) extractValue(result *TestResult, expression string) (interface{}, error) {
 	parts := strings.SplitN(expression, ":", 2)
 	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid extraction expression format: %s", expression)
+		return nil, fmt.Errorf("invalid extraction expression format: %s", expression)
 	}
 	
 	extractorType := parts[0]
@@ -49,31 +59,30 @@ func (ve *VariableExtractor) extractValue(result *TestResult, expression string)
 		return ve.extractFromJSON(result.Response, path)
 	case "header":
 		return ve.extractFromHeader(result.Headers, path)
+	case "cookie":
+		return ve.extractFromCookie(result.Headers, path)
 	case "regex":
 		return ve.extractFromRegex(result.Response, path)
 	case "css":
 		return ve.extractFromCSS(result.Response, path)
 	case "status":
-		return strconv.Itoa(result.StatusCode), nil
+		return result.StatusCode, nil
 	case "response_time":
-		return fmt.Sprintf("%.2f", float64(result.Duration.Nanoseconds())/1e6), nil
+		return math.Round(float64(result.Duration.Nanoseconds())/1e4) / 100, nil
 	default:
-		return "", fmt.Errorf("unsupported extractor type: %s", extractorType)
+		return nil, fmt.Errorf("unsupported extractor type: %s", extractorType)
 	}
 }
 
-func (ve *VariableExtractor) extractFromJSON(response, path string) (string, error) {
+func (ve *VariableExtractor) extractFromJSON(response, path string) (interface{}, error) {
 	var jsonData interface{}
-	if err := json.Unmarshal([]byte(response), &jsonData); err != nil {
-		return "", fmt.Errorf("failed to parse JSON response: %w", err)
-	}
-	
-	value, err := ve.getJSONPathValue(jsonData, path)
-	if err != nil {
-		return "", err
+	decoder := json.NewDecoder(strings.NewReader(response))
+	decoder.UseNumber()
+	if err := decoder.Decode(&jsonData); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 	
-	return fmt.Sprintf("%v", value), nil
+	return ve.getJSONPathValue(jsonData, path)
 }
 
 func (ve *VariableExtractor) extractFromHeader(headers map[string][]string, headerName string) (string, error) {
@@ -84,6 +93,26 @@ func (ve *VariableExtractor) extractFromHeader(headers map[string][]string, head
 	return values[0], nil
 }
 
+func (ve *VariableExtractor) extractFromCookie(headers map[string][]string, name string) (string, error) {
+	cookie := responseCookie(headers, name)
+	if cookie == nil {
+		return "", fmt.Errorf("cookie %s not found", name)
+	}
+	return cookie.Value, nil
+}
+
+// responseCookie returns the cookie with the given name set by the
+// Set-Cookie headers of a response, or nil
+func responseCookie(headers map[string][]string, name string) *http.Cookie {
+	response := &http.Response{Header: http.Header(headers)}
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
 func (ve *VariableExtractor) extractFromRegex(response, pattern string) (string, error) {
 	regex, err := regexp.Compile(pattern)
 	if err != nil {
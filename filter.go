@@ -0,0 +1,91 @@
+package goresttest
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TestFilter selects a subset of the tests of a suite
+type TestFilter struct {
+	// Tags selects tests that have at least one of these tags
+	Tags []string
+	// ExcludeTags drops tests that have any of these tags
+	ExcludeTags []string
+	// Name selects tests whose name matches the expression
+	Name *regexp.Regexp
+}
+
+// ParseTagList splits a comma separated list of tags, ignoring empty entries
+func ParseTagList(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// matches reports whether the filter selects test
+func (f TestFilter) matches(test Test) bool {
+	if len(f.Tags) > 0 && !hasAnyTag(test, f.Tags) {
+		return false
+	}
+	if hasAnyTag(test, f.ExcludeTags) {
+		return false
+	}
+	if f.Name != nil && !f.Name.MatchString(test.Name) {
+		return false
+	}
+	return true
+}
+
+func hasAnyTag(test Test, tags []string) bool {
+	for _, tag := range tags {
+		for _, testTag := range test.Tags {
+			if testTag == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterTests returns the tests selected by filter in their original order,
+// together with every test they transitively depend on so that the selected
+// tests can still run. Prerequisites are included even if the filter would
+// exclude them.
+func FilterTests(tests []Test, filter TestFilter) []Test {
+	index := make(map[string]int, len(tests))
+	for i, test := range tests {
+		index[test.Name] = i
+	}
+
+	selected := make([]bool, len(tests))
+	var include func(i int)
+	include = func(i int) {
+		if selected[i] {
+			return
+		}
+		selected[i] = true
+		for _, depName := range tests[i].DependsOn {
+			if depIndex, exists := index[depName]; exists {
+				include(depIndex)
+			}
+		}
+	}
+
+	for i, test := range tests {
+		if filter.matches(test) {
+			include(i)
+		}
+	}
+
+	var filtered []Test
+	for i, test := range tests {
+		if selected[i] {
+			filtered = append(filtered, test)
+		}
+	}
+	return filtered
+}
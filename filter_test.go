@@ -0,0 +1,69 @@
+package goresttest
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestFilterTests(t *testing.T) {
+	tests := []Test{
+		{Name: "Login", Tags: []string{"auth"}},
+		{Name: "Get Profile", Tags: []string{"smoke"}, DependsOn: []string{"Login"}},
+		{Name: "Export Data", Tags: []string{"slow"}, DependsOn: []string{"Get Profile"}},
+		{Name: "Health", Tags: []string{"smoke"}},
+		{Name: "Search", Tags: []string{"smoke", "slow"}},
+	}
+
+	cases := []struct {
+		name   string
+		filter TestFilter
+		want   []string
+	}{
+		{
+			name:   "no filter",
+			filter: TestFilter{},
+			want:   []string{"Login", "Get Profile", "Export Data", "Health", "Search"},
+		},
+		{
+			name:   "tags pull in prerequisites",
+			filter: TestFilter{Tags: []string{"smoke"}},
+			want:   []string{"Login", "Get Profile", "Health", "Search"},
+		},
+		{
+			name:   "exclude tags",
+			filter: TestFilter{Tags: []string{"smoke"}, ExcludeTags: []string{"slow"}},
+			want:   []string{"Login", "Get Profile", "Health"},
+		},
+		{
+			name:   "excluded prerequisite is still included",
+			filter: TestFilter{Tags: []string{"slow"}, ExcludeTags: []string{"auth"}},
+			want:   []string{"Login", "Get Profile", "Export Data", "Search"},
+		},
+		{
+			name:   "name pattern",
+			filter: TestFilter{Name: regexp.MustCompile("^(Health|Search)$")},
+			want:   []string{"Health", "Search"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var names []string
+			for _, test := range FilterTests(tests, tc.filter) {
+				names = append(names, test.Name)
+			}
+			if !reflect.DeepEqual(names, tc.want) {
+				t.Errorf("FilterTests() = %v, want %v", names, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTagList(t *testing.T) {
+	got := ParseTagList(" smoke, auth,,")
+	want := []string{"smoke", "auth"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTagList() = %v, want %v", got, want)
+	}
+}
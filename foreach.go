@@ -0,0 +1,144 @@
+package goresttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const defaultMaxIterations = 100
+
+// executeForEach runs a for_each test once per element of its input array
+// and returns a result that holds one result per iteration. The test passes
+// if every iteration passes.
+func (te *TestExecutor) executeForEach(ctx context.Context, test Test, currentVariables map[string]interface{}) (*TestResult, error) {
+	loop := test.ForEach
+
+	elements, err := loop.elements(currentVariables)
+	if err != nil {
+		err = fmt.Errorf("for_each: %w", err)
+		return &TestResult{Name: test.Name, Success: false, Error: err.Error()}, err
+	}
+
+	if len(elements) == 0 {
+		return &TestResult{
+			Name:       test.Name,
+			Status:     StatusSkipped,
+			SkipReason: fmt.Sprintf("for_each: %s has no elements", loop.In),
+		}, nil
+	}
+
+	as := loop.As
+	if as == "" {
+		as = "item"
+	}
+
+	aggregate := &TestResult{Name: test.Name, Success: true}
+	failed := 0
+	for i, element := range elements {
+		value, err := loop.bind(element)
+		if err != nil {
+			err = fmt.Errorf("for_each element %d: %w", i, err)
+			return &TestResult{Name: test.Name, Success: false, Error: err.Error()}, err
+		}
+
+		iterationVariables := make(map[string]interface{}, len(currentVariables)+2)
+		for k, v := range currentVariables {
+			iterationVariables[k] = v
+		}
+		iterationVariables[as] = value
+		iterationVariables[as+"_index"] = i
+
+		iteration := test
+		iteration.ForEach = nil
+		iteration.Name = InterpolateVariables(test.Name, iterationVariables)
+		if iteration.Name == test.Name {
+			iteration.Name = fmt.Sprintf("%s [%d]", test.Name, i)
+		}
+
+		// Iterations left when the suite is cancelled are reported as
+		// cancelled rather than dropped
+		var result *TestResult
+		if ctx.Err() != nil {
+			result = cancelledResult(iteration, ctx.Err())
+		} else {
+			result, err = te.executeWithRetry(ctx, iteration, iterationVariables)
+			if err != nil {
+				result.Success = false
+				result.Error = err.Error()
+			}
+			result.Name = iteration.Name
+			result.Status = resultStatus(result)
+			if err != nil && ctx.Err() != nil {
+				result.Status = StatusCancelled
+			}
+		}
+
+		if result.Status != StatusPassed {
+			aggregate.Success = false
+			failed++
+		}
+		aggregate.Duration += result.Duration
+		aggregate.Iterations = append(aggregate.Iterations, result)
+	}
+
+	if failed > 0 {
+		aggregate.Error = fmt.Sprintf("%d of %d iterations did not pass", failed, len(aggregate.Iterations))
+	}
+	if ctx.Err() != nil {
+		return aggregate, ctx.Err()
+	}
+	return aggregate, nil
+}
+
+// elements returns the array the loop iterates over, which is either a list
+// variable or JSON text
+func (f *ForEach) elements(variables map[string]interface{}) ([]interface{}, error) {
+	var source interface{}
+	if expression, ok := wholePlaceholder(f.In); ok {
+		value, err := resolvePlaceholder(expression, variables)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q is not defined", f.In)
+		}
+		source = value
+	} else if strings.Contains(f.In, "${") {
+		source = InterpolateVariables(f.In, variables)
+	} else {
+		value, exists := variables[f.In]
+		if !exists {
+			return nil, fmt.Errorf("variable %q is not defined", f.In)
+		}
+		source = value
+	}
+
+	var elements []interface{}
+	switch v := source.(type) {
+	case []interface{}:
+		elements = v
+	case string:
+		if err := json.Unmarshal([]byte(v), &elements); err != nil {
+			return nil, fmt.Errorf("%s is not a JSON array: %w", f.In, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s is not a JSON array", f.In)
+	}
+
+	maxIterations := f.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultMaxIterations
+	}
+	if len(elements) > maxIterations {
+		return nil, fmt.Errorf("%s has %d elements, more than max_iterations (%d)", f.In, len(elements), maxIterations)
+	}
+
+	return elements, nil
+}
+
+// bind returns the value bound to the loop variable for an element
+func (f *ForEach) bind(element interface{}) (interface{}, error) {
+	if f.Field != "" {
+		return NewVariableExtractor().getJSONPathValue(element, f.Field)
+	}
+	return element, nil
+}
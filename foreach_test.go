@@ -0,0 +1,139 @@
+package goresttest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTestExecutor_ForEach(t *testing.T) {
+	var mutex sync.Mutex
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requested = append(requested, r.URL.Path)
+		mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/users":
+			w.Write([]byte(`{"data": [{"id": 1, "name": "Leanne"}, {"id": 2, "name": "Ervin"}, {"id": 3, "name": "Clementine"}]}`))
+		case "/users/3":
+			w.WriteHeader(404)
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	suite := &TestSuite{
+		Tests: []Test{
+			{
+				Name:    "List Users",
+				URL:     "/users",
+				Extract: map[string]string{"users": "json:data"},
+			},
+			{
+				Name:       "Get User ${user_id}",
+				URL:        "/users/${user_id}",
+				DependsOn:  []string{"List Users"},
+				ForEach:    &ForEach{In: "${users}", As: "user_id", Field: "id"},
+				Assertions: statusOK(),
+			},
+			{
+				Name:      "After Users",
+				URL:       "/after",
+				DependsOn: []string{"Get User ${user_id}"},
+			},
+		},
+	}
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	var statuses []TestStatus
+	for _, result := range results {
+		names = append(names, result.Name)
+		statuses = append(statuses, result.Status)
+	}
+
+	wantNames := []string{"List Users", "Get User 1", "Get User 2", "Get User 3", "After Users"}
+	wantStatuses := []TestStatus{StatusPassed, StatusPassed, StatusPassed, StatusFailed, StatusSkipped}
+	if len(names) != len(wantNames) {
+		t.Fatalf("Expected results %v, got %v", wantNames, names)
+	}
+	for i := range wantNames {
+		if names[i] != wantNames[i] || statuses[i] != wantStatuses[i] {
+			t.Errorf("Result %d: expected %s %s, got %s %s", i, wantNames[i], wantStatuses[i], names[i], statuses[i])
+		}
+	}
+}
+
+func TestTestExecutor_ForEach_Cancel(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		Variables: map[string]interface{}{"ids": []interface{}{1, 2, 3}},
+		Tests: []Test{
+			{Name: "Get ${id}", URL: "/slow/${id}", ForEach: &ForEach{In: "${ids}", As: "id"}},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuiteContext(ctx, suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantNames := []string{"Get 1", "Get 2", "Get 3"}
+	if len(results) != len(wantNames) {
+		t.Fatalf("Expected a result for every iteration, got %d", len(results))
+	}
+	for i, result := range results {
+		if result.Name != wantNames[i] || result.Status != StatusCancelled {
+			t.Errorf("Result %d: expected %s cancelled, got %s %s", i, wantNames[i], result.Name, result.Status)
+		}
+	}
+	if requested := order(); len(requested) != 1 {
+		t.Errorf("Expected only the first iteration to be requested, got %v", requested)
+	}
+}
+
+func TestForEach_Elements(t *testing.T) {
+	variables := map[string]interface{}{
+		"ids":    `[1, 2, 3]`,
+		"object": `{"id": 1}`,
+	}
+
+	tests := []struct {
+		name      string
+		loop      ForEach
+		want      int
+		wantError bool
+	}{
+		{name: "variable name", loop: ForEach{In: "ids"}, want: 3},
+		{name: "placeholder", loop: ForEach{In: "${ids}"}, want: 3},
+		{name: "undefined", loop: ForEach{In: "missing"}, wantError: true},
+		{name: "not an array", loop: ForEach{In: "object"}, wantError: true},
+		{name: "over the cap", loop: ForEach{In: "ids", MaxIterations: 2}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements, err := tt.loop.elements(variables)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("elements() error = %v, wantError %v", err, tt.wantError)
+			}
+			if err == nil && len(elements) != tt.want {
+				t.Errorf("elements() returned %d elements, want %d", len(elements), tt.want)
+			}
+		})
+	}
+}
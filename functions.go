@@ -0,0 +1,341 @@
+package goresttest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateFunction implements a function that can be called from a
+// placeholder, e.g. ${uuid()}
+type templateFunction func(args []string) (string, error)
+
+// templateFunctions are the functions available in placeholders
+var templateFunctions = map[string]templateFunction{
+	"uuid":          uuidFunction,
+	"now":           nowFunction,
+	"timestamp":     timestampFunction,
+	"timestamp_ms":  timestampMsFunction,
+	"random_int":    randomIntFunction,
+	"random_string": randomStringFunction,
+	"base64":        base64Function,
+	"sha256":        sha256Function,
+	"urlencode":     urlencodeFunction,
+}
+
+// timeLayouts maps the layout names accepted by now() to Go time layouts
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+const randomStringAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func expectArgs(name string, args []string, min, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("%s() takes %d arguments, got %d", name, min, len(args))
+		}
+		return fmt.Errorf("%s() takes %d to %d arguments, got %d", name, min, max, len(args))
+	}
+	return nil
+}
+
+// uuidFunction returns a random version 4 UUID
+func uuidFunction(args []string) (string, error) {
+	if err := expectArgs("uuid", args, 0, 0); err != nil {
+		return "", err
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// nowFunction returns the current UTC time formatted with a named layout,
+// such as RFC3339 (the default), or a Go time layout
+func nowFunction(args []string) (string, error) {
+	if err := expectArgs("now", args, 0, 1); err != nil {
+		return "", err
+	}
+	layout := time.RFC3339
+	if len(args) == 1 {
+		layout = args[0]
+		if named, exists := timeLayouts[layout]; exists {
+			layout = named
+		}
+	}
+	return time.Now().UTC().Format(layout), nil
+}
+
+// timestampFunction returns the current Unix time in seconds
+func timestampFunction(args []string) (string, error) {
+	if err := expectArgs("timestamp", args, 0, 0); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(time.Now().Unix(), 10), nil
+}
+
+// timestampMsFunction returns the current Unix time in milliseconds
+func timestampMsFunction(args []string) (string, error) {
+	if err := expectArgs("timestamp_ms", args, 0, 0); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(time.Now().UnixMilli(), 10), nil
+}
+
+// randomIntFunction returns a random integer between min and max, inclusive
+func randomIntFunction(args []string) (string, error) {
+	if err := expectArgs("random_int", args, 2, 2); err != nil {
+		return "", err
+	}
+	min, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("random_int(): invalid minimum %q", args[0])
+	}
+	max, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("random_int(): invalid maximum %q", args[1])
+	}
+	if max < min {
+		return "", fmt.Errorf("random_int(): maximum %d is less than minimum %d", max, min)
+	}
+
+	// The range of the full int64 interval does not fit an int64
+	size := new(big.Int).Sub(big.NewInt(max), big.NewInt(min))
+	n, err := rand.Int(rand.Reader, size.Add(size, big.NewInt(1)))
+	if err != nil {
+		return "", err
+	}
+	return n.Add(n, big.NewInt(min)).String(), nil
+}
+
+// randomStringFunction returns a random alphanumeric string of the given length
+func randomStringFunction(args []string) (string, error) {
+	if err := expectArgs("random_string", args, 1, 1); err != nil {
+		return "", err
+	}
+	length, err := strconv.Atoi(args[0])
+	if err != nil || length < 0 {
+		return "", fmt.Errorf("random_string(): invalid length %q", args[0])
+	}
+
+	alphabetSize := big.NewInt(int64(len(randomStringAlphabet)))
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		b[i] = randomStringAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// base64Function returns the standard base64 encoding of its argument
+func base64Function(args []string) (string, error) {
+	if err := expectArgs("base64", args, 1, 1); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(args[0])), nil
+}
+
+// sha256Function returns the hex encoded SHA-256 digest of its argument
+func sha256Function(args []string) (string, error) {
+	if err := expectArgs("sha256", args, 1, 1); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(args[0]))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// urlencodeFunction escapes its argument for use in a URL query
+func urlencodeFunction(args []string) (string, error) {
+	if err := expectArgs("urlencode", args, 1, 1); err != nil {
+		return "", err
+	}
+	return url.QueryEscape(args[0]), nil
+}
+
+// functionCall is a parsed placeholder of the form name(arg, ...)
+type functionCall struct {
+	name string
+	args []string
+}
+
+// parseFunctionCall parses a placeholder expression as a function call. It
+// returns false if the expression is not a call.
+func parseFunctionCall(expression string) (functionCall, bool) {
+	expression = strings.TrimSpace(expression)
+	open := strings.IndexByte(expression, '(')
+	if open <= 0 || !strings.HasSuffix(expression, ")") || !isIdentifier(expression[:open]) {
+		return functionCall{}, false
+	}
+
+	call := functionCall{name: expression[:open]}
+	inner := strings.TrimSpace(expression[open+1 : len(expression)-1])
+	if inner == "" {
+		return call, true
+	}
+
+	args, ok := splitArguments(inner)
+	if !ok {
+		return functionCall{}, false
+	}
+	call.args = args
+	return call, true
+}
+
+// splitArguments splits a function argument list at top-level commas. It
+// returns false if quotes, parentheses or braces are unbalanced.
+func splitArguments(list string) ([]string, bool) {
+	var args []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '{':
+			depth++
+		case c == ')' || c == '}':
+			depth--
+			if depth < 0 {
+				return nil, false
+			}
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		}
+	}
+	if quote != 0 || depth != 0 {
+		return nil, false
+	}
+	return append(args, strings.TrimSpace(list[start:])), true
+}
+
+// evaluate calls the function with its arguments resolved against variables
+func (c functionCall) evaluate(variables map[string]interface{}) (string, error) {
+	function, exists := templateFunctions[c.name]
+	if !exists {
+		return "", fmt.Errorf("unknown function %q", c.name)
+	}
+
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		value, err := evaluateArgument(arg, variables)
+		if err != nil {
+			return "", fmt.Errorf("%s(): %w", c.name, err)
+		}
+		args[i] = value
+	}
+	return function(args)
+}
+
+// evaluateArgument resolves a function argument. Arguments are quoted
+// strings, which may contain placeholders, numbers, ${...} placeholders,
+// nested function calls or variable names with an optional default.
+func evaluateArgument(arg string, variables map[string]interface{}) (string, error) {
+	if text, quoted := unquoteArgument(arg); quoted {
+		return InterpolateVariables(text, variables), nil
+	}
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		return arg, nil
+	}
+	if strings.HasPrefix(arg, "${") && strings.HasSuffix(arg, "}") {
+		arg = arg[2 : len(arg)-1]
+	}
+	value, err := resolvePlaceholder(arg, variables)
+	if errors.Is(err, errUndefinedVariable) {
+		return "", fmt.Errorf("variable %q is not defined", arg)
+	}
+	if err != nil {
+		return "", err
+	}
+	return formatVariable(value), nil
+}
+
+// unquoteArgument returns the content of a single or double quoted argument.
+// Double quoted arguments support Go escape sequences.
+func unquoteArgument(arg string) (string, bool) {
+	if len(arg) < 2 {
+		return "", false
+	}
+	switch {
+	case arg[0] == '"' && arg[len(arg)-1] == '"':
+		if text, err := strconv.Unquote(arg); err == nil {
+			return text, true
+		}
+	case arg[0] == '\'' && arg[len(arg)-1] == '\'':
+		return arg[1 : len(arg)-1], true
+	}
+	return "", false
+}
+
+// substitute returns the call with its arguments interpolated from variables
+// but without calling any function, and whether any argument changed.
+// Data-driven tests use it to bind row values while leaving functions such as
+// uuid() to be evaluated per request.
+func (c functionCall) substitute(variables map[string]string) (string, bool) {
+	args := make([]string, len(c.args))
+	changed := false
+	for i, arg := range c.args {
+		args[i] = arg
+		if text, quoted := unquoteArgument(arg); quoted {
+			if interpolated := interpolateRowVariables(text, variables); interpolated != text {
+				args[i] = strconv.Quote(interpolated)
+				changed = true
+			}
+			continue
+		}
+
+		expression := arg
+		if strings.HasPrefix(arg, "${") && strings.HasSuffix(arg, "}") {
+			expression = arg[2 : len(arg)-1]
+		}
+		if call, ok := parseFunctionCall(expression); ok {
+			if substituted, callChanged := call.substitute(variables); callChanged {
+				args[i] = substituted
+				changed = true
+			}
+		} else if value, exists := variables[expression]; exists {
+			args[i] = strconv.Quote(value)
+			changed = true
+		}
+	}
+	return fmt.Sprintf("%s(%s)", c.name, strings.Join(args, ", ")), changed
+}
+
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && (i == 0 || !('0' <= c && c <= '9')) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,94 @@
+package goresttest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterpolateVariables_Functions(t *testing.T) {
+	t.Setenv("GORESTTEST_SECRET", "s3cret")
+
+	variables := map[string]interface{}{"user": "alice", "pass": "pa:ss", "query": "a b&c"}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{`${base64("alice:pa:ss")}`, "YWxpY2U6cGE6c3M="},
+		{`${base64("${user}:${pass}")}`, "YWxpY2U6cGE6c3M="},
+		{`${base64(user)}`, "YWxpY2U="},
+		{`${base64(${user})}`, "YWxpY2U="},
+		{`${sha256("abc")}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`/search?q=${urlencode(query)}`, "/search?q=a+b%26c"},
+		{`${base64(env:GORESTTEST_SECRET)}`, "czNjcmV0"},
+		{`${base64(sha256('abc'))}`, "YmE3ODE2YmY4ZjAxY2ZlYTQxNDE0MGRlNWRhZTIyMjNiMDAzNjFhMzk2MTc3YTljYjQxMGZmNjFmMjAwMTVhZA=="},
+		{`${base64("a}b")}`, "YX1i"},
+		{`${now("2006")}`, strconv.Itoa(time.Now().UTC().Year())},
+		{`${random_int(7, 7)}`, "7"},
+		{`${unknown()} ${base64(missing)} ${random_int(1)}`, `${unknown()} ${base64(missing)} ${random_int(1)}`},
+	}
+
+	for _, tt := range tests {
+		if got := InterpolateVariables(tt.text, variables); got != tt.expected {
+			t.Errorf("InterpolateVariables(%q): expected %q, got %q", tt.text, tt.expected, got)
+		}
+	}
+}
+
+func TestInterpolateVariables_Generators(t *testing.T) {
+	patterns := map[string]string{
+		"${uuid()}":             `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		"${now()}":              `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`,
+		`${now("DateOnly")}`:    `^\d{4}-\d{2}-\d{2}$`,
+		"${timestamp()}":        `^\d{10}$`,
+		"${timestamp_ms()}":     `^\d{13}$`,
+		"${random_int(1, 100)}": `^([1-9]|[1-9]\d|100)$`,
+		"${random_int(-9223372036854775808, 9223372036854775807)}": `^-?\d{1,19}$`,
+		"${random_int(-5, -3)}": `^-[345]$`,
+		"${random_string(12)}":  `^[A-Za-z0-9]{12}$`,
+	}
+
+	for text, pattern := range patterns {
+		got := InterpolateVariables(text, nil)
+		if !regexp.MustCompile(pattern).MatchString(got) {
+			t.Errorf("InterpolateVariables(%q): %q does not match %s", text, got, pattern)
+		}
+	}
+
+	if InterpolateVariables("${uuid()}", nil) == InterpolateVariables("${uuid()}", nil) {
+		t.Error("Expected uuid() to return a different value on every call")
+	}
+}
+
+func TestParseTestSuiteFromString_DataDrivenFunctions(t *testing.T) {
+	yamlContent := `
+name: "Users"
+tests:
+  - name: "Create ${name}"
+    method: "POST"
+    url: "/users"
+    body: '{"id": "${uuid()}", "token": "${base64(name)}", "hash": "${sha256("${name}-${salt}")}"}'
+    data:
+      - name: "alice"
+`
+
+	suite, err := ParseTestSuiteFromString(yamlContent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Functions are evaluated per request, with the row variables
+	test := suite.Tests[0]
+	variables := mergeVariables(test.variables, map[string]interface{}{"salt": "s"})
+	pattern := `^\{"id": "[0-9a-f-]{36}", "token": "YWxpY2U=", "hash": "[0-9a-f]{64}"\}$`
+	first, second := InterpolateVariables(test.Body, variables), InterpolateVariables(test.Body, variables)
+	if !regexp.MustCompile(pattern).MatchString(first) || first == second {
+		t.Errorf("Expected a new uuid and the row values in every body, got %s and %s", first, second)
+	}
+	if want := sha256Hex("alice-s"); !strings.Contains(first, want) {
+		t.Errorf("Expected hash %s of the row value, got %s", want, first)
+	}
+}
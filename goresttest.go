@@ -1,6 +1,8 @@
 // Package goresttest provides REST API testing functionality
 package goresttest
 
+import "context"
+
 // TestRunner provides the main interface for running REST API tests
 type TestRunner struct {
 	executor *TestExecutor
@@ -13,15 +15,74 @@ func NewTestRunner(baseURL string) *TestRunner {
 	}
 }
 
+// RunOption configures a single run of a test suite
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	environment string
+	variables   map[string]interface{}
+}
+
+// WithEnvironment runs the suite against one of its environments, without
+// modifying the suite itself
+func WithEnvironment(name string) RunOption {
+	return func(o *runOptions) {
+		o.environment = name
+	}
+}
+
+// WithVariables overrides variables of the suite, and of the selected
+// environment, for a single run
+func WithVariables(variables map[string]interface{}) RunOption {
+	return func(o *runOptions) {
+		o.variables = mergeVariables(o.variables, variables)
+	}
+}
+
 // RunTestSuite executes a test suite and returns the results
-func (tr *TestRunner) RunTestSuite(suite *TestSuite) ([]*TestResult, error) {
-	return tr.executor.ExecuteTestSuite(suite)
+func (tr *TestRunner) RunTestSuite(suite *TestSuite, opts ...RunOption) ([]*TestResult, error) {
+	return tr.RunTestSuiteContext(context.Background(), suite, opts...)
+}
+
+// RunTestSuiteContext executes a test suite and returns the results. When ctx
+// is cancelled no further tests are started, in-flight requests are aborted
+// and the unfinished tests are reported as cancelled.
+func (tr *TestRunner) RunTestSuiteContext(ctx context.Context, suite *TestSuite, opts ...RunOption) ([]*TestResult, error) {
+	var options runOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.environment != "" || len(options.variables) > 0 {
+		configured := *suite
+		if options.environment != "" {
+			if err := configured.ApplyEnvironment(options.environment); err != nil {
+				return nil, err
+			}
+		}
+		configured.OverrideVariables(options.variables)
+		suite = &configured
+	}
+
+	return tr.executor.ExecuteTestSuiteContext(ctx, suite)
 }
 
 // RunTest executes a single test and returns the result
-func (tr *TestRunner) RunTest(test Test, variables map[string]string) (*TestResult, error) {
+func (tr *TestRunner) RunTest(test Test, variables map[string]interface{}) (*TestResult, error) {
+	return tr.RunTestContext(context.Background(), test, variables)
+}
+
+// RunTestContext executes a single test bound to ctx and returns the result
+func (tr *TestRunner) RunTestContext(ctx context.Context, test Test, variables map[string]interface{}) (*TestResult, error) {
 	if variables != nil {
 		tr.executor.globalVariables = variables
 	}
-	return tr.executor.executeTest(test)
+	result, err := tr.executor.executeTest(ctx, test)
+	if result != nil {
+		result.Status = resultStatus(result)
+		if err != nil && ctx.Err() != nil {
+			result.Status = StatusCancelled
+		}
+	}
+	return result, err
 }
\ No newline at end of file
@@ -0,0 +1,261 @@
+package goresttest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DependencyErrorKind identifies the kind of problem found in the dependency
+// graph of a test suite.
+type DependencyErrorKind string
+
+const (
+	// DependencyUnknown means a depends_on entry names a test that does not exist.
+	DependencyUnknown DependencyErrorKind = "unknown_dependency"
+	// DependencyCycle means tests depend on each other in a loop. The cycle
+	// is reported in depends_on direction, e.g. "A -> B -> A".
+	DependencyCycle DependencyErrorKind = "cycle"
+	// DependencyDuplicateName means more than one test uses the same name.
+	DependencyDuplicateName DependencyErrorKind = "duplicate_name"
+)
+
+// DependencyError describes a single problem in the dependency graph
+type DependencyError struct {
+	Kind       DependencyErrorKind
+	Test       string
+	Dependency string
+	Cycle      []string
+	// Source is the file and line where Test is defined, if known
+	Source string
+}
+
+func (e *DependencyError) Error() string {
+	var message string
+	switch e.Kind {
+	case DependencyUnknown:
+		message = fmt.Sprintf("test %q depends on unknown test %q", e.Test, e.Dependency)
+	case DependencyCycle:
+		message = fmt.Sprintf("dependency cycle: %s", strings.Join(e.Cycle, " -> "))
+	case DependencyDuplicateName:
+		message = fmt.Sprintf("duplicate test name %q", e.Test)
+	default:
+		message = fmt.Sprintf("invalid dependency for test %q", e.Test)
+	}
+
+	if e.Source != "" {
+		message += fmt.Sprintf(" (%s)", e.Source)
+	}
+	return message
+}
+
+// DependencyErrors collects every problem found while validating a dependency graph
+type DependencyErrors []*DependencyError
+
+func (e DependencyErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid test dependencies: %s", strings.Join(messages, "; "))
+}
+
+// ValidateDependencies checks that test names are unique, that every
+// depends_on entry names an existing test and that there are no cycles. It
+// returns DependencyErrors listing every problem found, or nil.
+func ValidateDependencies(tests []Test) error {
+	var errs DependencyErrors
+
+	seen := make(map[string]bool, len(tests))
+	for _, test := range tests {
+		if seen[test.Name] {
+			errs = append(errs, &DependencyError{Kind: DependencyDuplicateName, Test: test.Name, Source: test.source.String()})
+		}
+		seen[test.Name] = true
+	}
+
+	for _, test := range tests {
+		for _, depName := range test.DependsOn {
+			if !seen[depName] {
+				errs = append(errs, &DependencyError{Kind: DependencyUnknown, Test: test.Name, Dependency: depName, Source: test.source.String()})
+			}
+		}
+	}
+
+	graph := newDependencyGraph(tests)
+	for _, cycle := range graph.cycles() {
+		first := tests[graph.index[cycle[0]]]
+		errs = append(errs, &DependencyError{Kind: DependencyCycle, Test: cycle[0], Cycle: cycle, Source: first.source.String()})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateSuite validates the dependency graphs of the setup, tests and
+// teardown phases of a suite. Test names must be unique across all phases and
+// depends_on entries may only name tests of the same phase.
+func ValidateSuite(suite *TestSuite) error {
+	var errs DependencyErrors
+
+	seen := make(map[string]bool)
+	for _, tests := range [][]Test{suite.Setup, suite.Tests, suite.Teardown} {
+		for _, test := range tests {
+			if seen[test.Name] {
+				errs = append(errs, &DependencyError{Kind: DependencyDuplicateName, Test: test.Name, Source: test.source.String()})
+			}
+		}
+		for _, test := range tests {
+			seen[test.Name] = true
+		}
+
+		if err := ValidateDependencies(tests); err != nil {
+			errs = append(errs, err.(DependencyErrors)...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// dependencyGraph describes the depends_on relationships between the tests of
+// a suite. Tests are referred to by their index in the original slice.
+type dependencyGraph struct {
+	tests      []Test
+	index      map[string]int
+	dependents [][]int
+}
+
+// scheduledResult is reported by a worker once it is done with a test.
+type scheduledResult struct {
+	index  int
+	result *TestResult
+}
+
+func newDependencyGraph(tests []Test) *dependencyGraph {
+	graph := &dependencyGraph{
+		tests:      tests,
+		index:      make(map[string]int, len(tests)),
+		dependents: make([][]int, len(tests)),
+	}
+
+	for i, test := range tests {
+		if _, exists := graph.index[test.Name]; !exists {
+			graph.index[test.Name] = i
+		}
+	}
+
+	for i, test := range tests {
+		for _, depName := range test.DependsOn {
+			if depIndex, exists := graph.index[depName]; exists {
+				graph.dependents[depIndex] = append(graph.dependents[depIndex], i)
+			}
+		}
+	}
+
+	return graph
+}
+
+// pendingCounts returns, for every test, the number of dependencies that have
+// to finish before the test can be scheduled. Dependencies on unknown tests
+// are not counted; runTest skips those tests once they are released.
+func (g *dependencyGraph) pendingCounts() []int {
+	counts := make([]int, len(g.tests))
+	for _, dependents := range g.dependents {
+		for _, dependent := range dependents {
+			counts[dependent]++
+		}
+	}
+	return counts
+}
+
+// order returns the test indices in an order where every test comes after
+// its dependencies. Among tests that are ready at the same time the original
+// order is kept. Tests that are part of a cycle are left out.
+func (g *dependencyGraph) order() []int {
+	pending := g.pendingCounts()
+
+	var ready []int
+	for index, count := range pending {
+		if count == 0 {
+			ready = append(ready, index)
+		}
+	}
+
+	order := make([]int, 0, len(g.tests))
+	for len(ready) > 0 {
+		index := ready[0]
+		ready = ready[1:]
+		order = append(order, index)
+
+		for _, dependent := range g.dependents[index] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+				sort.Ints(ready)
+			}
+		}
+	}
+
+	return order
+}
+
+// cycles returns every dependency cycle found by a depth-first search. Each
+// cycle lists the test names in depends_on direction, with the first name
+// repeated at the end.
+func (g *dependencyGraph) cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(g.tests))
+	var stack []int
+	var cycles [][]string
+
+	var visit func(index int)
+	visit = func(index int) {
+		state[index] = visiting
+		stack = append(stack, index)
+
+		for _, depName := range g.tests[index].DependsOn {
+			depIndex, exists := g.index[depName]
+			if !exists {
+				continue
+			}
+
+			switch state[depIndex] {
+			case unvisited:
+				visit(depIndex)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != depIndex {
+					start--
+				}
+
+				var cycle []string
+				for _, cycleIndex := range stack[start:] {
+					cycle = append(cycle, g.tests[cycleIndex].Name)
+				}
+				cycle = append(cycle, g.tests[depIndex].Name)
+				cycles = append(cycles, cycle)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[index] = visited
+	}
+
+	for index := range g.tests {
+		if state[index] == unvisited {
+			visit(index)
+		}
+	}
+
+	return cycles
+}
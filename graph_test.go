@@ -0,0 +1,143 @@
+package goresttest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	tests := []struct {
+		name  string
+		tests []Test
+		want  []*DependencyError
+	}{
+		{
+			name: "valid chain",
+			tests: []Test{
+				{Name: "A"},
+				{Name: "B", DependsOn: []string{"A"}},
+				{Name: "C", DependsOn: []string{"A", "B"}},
+			},
+		},
+		{
+			name: "unknown dependency",
+			tests: []Test{
+				{Name: "A", DependsOn: []string{"Missing"}},
+			},
+			want: []*DependencyError{
+				{Kind: DependencyUnknown, Test: "A", Dependency: "Missing"},
+			},
+		},
+		{
+			name: "duplicate name",
+			tests: []Test{
+				{Name: "A"},
+				{Name: "A"},
+			},
+			want: []*DependencyError{
+				{Kind: DependencyDuplicateName, Test: "A"},
+			},
+		},
+		{
+			name: "three test cycle",
+			tests: []Test{
+				{Name: "A", DependsOn: []string{"C"}},
+				{Name: "B", DependsOn: []string{"A"}},
+				{Name: "C", DependsOn: []string{"B"}},
+				{Name: "D", DependsOn: []string{"A"}},
+			},
+			want: []*DependencyError{
+				{Kind: DependencyCycle, Test: "A", Cycle: []string{"A", "C", "B", "A"}},
+			},
+		},
+		{
+			name: "self dependency",
+			tests: []Test{
+				{Name: "A", DependsOn: []string{"A"}},
+			},
+			want: []*DependencyError{
+				{Kind: DependencyCycle, Test: "A", Cycle: []string{"A", "A"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDependencies(tt.tests)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var errs DependencyErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Expected DependencyErrors, got %v", err)
+			}
+			if !reflect.DeepEqual([]*DependencyError(errs), tt.want) {
+				t.Errorf("ValidateDependencies() = %v, want %v", errs, DependencyErrors(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseTestSuiteFromString_InvalidDependencies(t *testing.T) {
+	yamlContent := `
+name: "Broken"
+tests:
+  - name: "Get Profile"
+    url: "/profile"
+    depends_on: ["Login"]
+`
+
+	_, err := ParseTestSuiteFromString(yamlContent)
+	var errs DependencyErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected DependencyErrors, got %v", err)
+	}
+	if len(errs) != 1 || errs[0].Kind != DependencyUnknown || errs[0].Dependency != "Login" {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+}
+
+func TestDependencyGraph_Order(t *testing.T) {
+	tests := []Test{
+		{Name: "Profile", DependsOn: []string{"Login"}},
+		{Name: "Health"},
+		{Name: "Login"},
+	}
+
+	var names []string
+	for _, index := range newDependencyGraph(tests).order() {
+		names = append(names, tests[index].Name)
+	}
+
+	want := []string{"Health", "Login", "Profile"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("order() = %v, want %v", names, want)
+	}
+}
+
+func TestValidateSuite(t *testing.T) {
+	suite := &TestSuite{
+		Setup:    []Test{{Name: "Login"}},
+		Tests:    []Test{{Name: "Profile", DependsOn: []string{"Login"}}},
+		Teardown: []Test{{Name: "Login"}},
+	}
+
+	err := ValidateSuite(suite)
+	var errs DependencyErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected DependencyErrors, got %v", err)
+	}
+
+	want := []*DependencyError{
+		{Kind: DependencyUnknown, Test: "Profile", Dependency: "Login"},
+		{Kind: DependencyDuplicateName, Test: "Login"},
+	}
+	if !reflect.DeepEqual([]*DependencyError(errs), want) {
+		t.Errorf("ValidateSuite() = %v, want %v", errs, DependencyErrors(want))
+	}
+}
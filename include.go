@@ -0,0 +1,286 @@
+package goresttest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sourcePos records where a test was defined
+type sourcePos struct {
+	file string
+	line int
+}
+
+func (p sourcePos) String() string {
+	if p.line == 0 {
+		return ""
+	}
+	if p.file == "" {
+		return fmt.Sprintf("line %d", p.line)
+	}
+	return fmt.Sprintf("%s:%d", p.file, p.line)
+}
+
+// describeTest returns the quoted name of a test followed by its position
+func describeTest(test Test) string {
+	if source := test.source.String(); source != "" {
+		return fmt.Sprintf("%q (%s)", test.Name, source)
+	}
+	return fmt.Sprintf("%q", test.Name)
+}
+
+// suiteLoader reads suite files and resolves their include directives
+type suiteLoader struct {
+	// loading holds the files currently being loaded, to detect cycles
+	loading []string
+	// loaded holds every file loaded so far, so each is included once
+	loaded map[string]bool
+	// problems holds the validation problems found in every loaded file
+	problems ValidationErrors
+}
+
+func newSuiteLoader() *suiteLoader {
+	return &suiteLoader{loaded: make(map[string]bool)}
+}
+
+// loadFile reads and decodes a suite file and the files it includes
+func (l *suiteLoader) loadFile(filename string) (*TestSuite, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %s: %w", filename, err)
+	}
+
+	for i, loading := range l.loading {
+		if loading == path {
+			cycle := append(append([]string(nil), l.loading[i:]...), path)
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	l.loading = append(l.loading, path)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+	l.loaded[path] = true
+
+	return l.load(data, filename, filepath.Dir(filename))
+}
+
+// load decodes a suite read from filename, which may be empty for suites
+// that do not come from a file. Relative paths are resolved against dir.
+func (l *suiteLoader) load(data []byte, filename, dir string) (*TestSuite, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, parseError(filename, err)
+	}
+
+	l.problems = append(l.problems, validateSuiteNode(&root, filename)...)
+
+	var suite TestSuite
+	if len(root.Content) > 0 {
+		if err := root.Decode(&suite); err != nil {
+			// Type errors leave the rest of the suite decoded, so they are
+			// reported with any other problem once loading completes.
+			problems, ok := typeErrors(err, filename)
+			if !ok {
+				return nil, parseError(filename, err)
+			}
+			l.problems = append(l.problems, problems...)
+		}
+		annotateSources(root.Content[0], &suite, filename)
+	}
+
+	resolveDataPaths(&suite, dir)
+
+	if err := l.mergeIncludes(&suite, dir); err != nil {
+		return nil, err
+	}
+
+	return &suite, nil
+}
+
+// result returns the suite loaded by the loader, or every validation problem
+// found while loading it
+func (l *suiteLoader) result(suite *TestSuite, err error) (*TestSuite, error) {
+	if len(l.problems) > 0 {
+		return nil, l.problems
+	}
+	return suite, err
+}
+
+func parseError(filename string, err error) error {
+	if filename == "" {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return fmt.Errorf("failed to parse YAML in %s: %w", filename, err)
+}
+
+// mergeIncludes merges the files named by the suite's include directive into
+// it. Included tests come first, in include order, followed by the suite's
+// own. Variables, templates and environments of later includes override
+// earlier ones, and the suite's own override all included ones. Every other
+// setting is taken from the including suite only.
+func (l *suiteLoader) mergeIncludes(suite *TestSuite, dir string) error {
+	if len(suite.Include) == 0 {
+		return nil
+	}
+
+	var files []string
+	for _, pattern := range suite.Include {
+		fullPattern := pattern
+		if !filepath.IsAbs(fullPattern) {
+			fullPattern = filepath.Join(dir, fullPattern)
+		}
+
+		matches, err := filepath.Glob(fullPattern)
+		if err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("include %q did not match any file", pattern)
+		}
+		files = append(files, matches...)
+	}
+
+	variables := make(map[string]interface{})
+	templates := make(map[string]Test)
+	environments := make(map[string]Environment)
+	var setup, tests, teardown []Test
+
+	for _, file := range files {
+		path, err := filepath.Abs(file)
+		if err == nil && l.loaded[path] && !l.isLoading(path) {
+			continue
+		}
+
+		included, err := l.loadFile(file)
+		if err != nil {
+			return err
+		}
+
+		for k, v := range included.Variables {
+			variables[k] = v
+		}
+		for k, v := range included.Templates {
+			templates[k] = v
+		}
+		for k, v := range included.Environments {
+			environments[k] = v
+		}
+		setup = append(setup, included.Setup...)
+		tests = append(tests, included.Tests...)
+		teardown = append(teardown, included.Teardown...)
+	}
+
+	for k, v := range suite.Variables {
+		variables[k] = v
+	}
+	for k, v := range suite.Templates {
+		templates[k] = v
+	}
+	for k, v := range suite.Environments {
+		environments[k] = v
+	}
+
+	suite.Variables = variables
+	suite.Templates = templates
+	suite.Environments = environments
+	suite.Setup = append(setup, suite.Setup...)
+	suite.Tests = append(tests, suite.Tests...)
+	suite.Teardown = append(teardown, suite.Teardown...)
+
+	defined := make(map[string]Test)
+	for _, phase := range [][]Test{suite.Setup, suite.Tests, suite.Teardown} {
+		for _, test := range phase {
+			if previous, exists := defined[test.Name]; exists {
+				return fmt.Errorf("duplicate test name %q: defined at %s and %s", test.Name, previous.source, test.source)
+			}
+			defined[test.Name] = test
+		}
+	}
+
+	return nil
+}
+
+func (l *suiteLoader) isLoading(path string) bool {
+	for _, loading := range l.loading {
+		if loading == path {
+			return true
+		}
+	}
+	return false
+}
+
+// annotateSources records the position of every test and template defined in
+// the mapping node of a suite
+func annotateSources(node *yaml.Node, suite *TestSuite, filename string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+
+		var tests []Test
+		switch key {
+		case "setup":
+			tests = suite.Setup
+		case "tests":
+			tests = suite.Tests
+		case "teardown":
+			tests = suite.Teardown
+		case "templates":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				name := value.Content[j].Value
+				if template, exists := suite.Templates[name]; exists {
+					template.source = sourcePos{file: filename, line: value.Content[j].Line}
+					suite.Templates[name] = template
+				}
+			}
+			continue
+		default:
+			continue
+		}
+
+		for j := range tests {
+			if j < len(value.Content) {
+				tests[j].source = sourcePos{file: filename, line: value.Content[j].Line}
+			}
+		}
+	}
+}
+
+// resolveDataPaths makes relative data file and TLS file paths relative to
+// dir
+func resolveDataPaths(suite *TestSuite, dir string) {
+	if suite.TLS != nil {
+		for _, path := range []*string{&suite.TLS.CACert, &suite.TLS.ClientCert, &suite.TLS.ClientKey} {
+			if *path != "" && !filepath.IsAbs(*path) && !hasPlaceholder(*path) {
+				*path = filepath.Join(dir, *path)
+			}
+		}
+	}
+
+	resolve := func(test *Test) {
+		if test.Data != nil && test.Data.File != "" && !filepath.IsAbs(test.Data.File) {
+			test.Data.File = filepath.Join(dir, test.Data.File)
+		}
+	}
+
+	for _, tests := range [][]Test{suite.Setup, suite.Tests, suite.Teardown} {
+		for i := range tests {
+			resolve(&tests[i])
+		}
+	}
+	for name, template := range suite.Templates {
+		resolve(&template)
+		suite.Templates[name] = template
+	}
+}
@@ -0,0 +1,180 @@
+package goresttest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSuiteFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestParseTestSuite_Include(t *testing.T) {
+	dir := writeSuiteFiles(t, map[string]string{
+		"main.yaml": `
+name: "Main"
+base_url: "https://api.example.com"
+include:
+  - "common.yaml"
+  - "features/*.yaml"
+variables:
+  user: "main"
+tests:
+  - name: "Main Test"
+    url: "/main"
+`,
+		"common.yaml": `
+name: "Common"
+base_url: "https://ignored.example.com"
+variables:
+  user: "common"
+  token: "common"
+templates:
+  authed:
+    headers:
+      Authorization: "Bearer ${token}"
+setup:
+  - name: "Login"
+    url: "/login"
+`,
+		"features/a.yaml": `
+variables:
+  token: "a"
+tests:
+  - name: "Feature A"
+    extends: "authed"
+    url: "/a"
+`,
+		"features/b.yaml": `
+include: ["../common.yaml"]
+tests:
+  - name: "Feature B"
+    url: "/b"
+`,
+	})
+
+	suite, err := ParseTestSuite(filepath.Join(dir, "main.yaml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if suite.Name != "Main" || suite.BaseURL != "https://api.example.com" {
+		t.Errorf("Expected settings of the including suite, got %q %q", suite.Name, suite.BaseURL)
+	}
+
+	var names []string
+	for _, test := range suite.Tests {
+		names = append(names, test.Name)
+	}
+	if strings.Join(names, ",") != "Feature A,Feature B,Main Test" {
+		t.Errorf("Expected included tests before own tests, got %v", names)
+	}
+
+	if len(suite.Setup) != 1 || suite.Setup[0].Name != "Login" {
+		t.Errorf("Expected included setup test Login once, got %v", suite.Setup)
+	}
+
+	if suite.Variables["user"] != "main" {
+		t.Errorf("Expected own variable to override included, got %q", suite.Variables["user"])
+	}
+	if suite.Variables["token"] != "a" {
+		t.Errorf("Expected later include to override earlier, got %q", suite.Variables["token"])
+	}
+
+	if suite.Tests[0].Headers["Authorization"] != "Bearer ${token}" {
+		t.Errorf("Expected included template to apply, got %v", suite.Tests[0].Headers)
+	}
+}
+
+func TestParseTestSuite_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name: "duplicate test name",
+			files: map[string]string{
+				"main.yaml":  "include: [\"other.yaml\"]\ntests:\n  - name: \"Login\"\n    url: \"/a\"\n",
+				"other.yaml": "tests:\n  - name: \"First\"\n    url: \"/first\"\n  - name: \"Login\"\n    url: \"/b\"\n",
+			},
+			expected: []string{`duplicate test name "Login"`, "other.yaml:4", "main.yaml:3"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.yaml": "include: [\"a.yaml\"]\n",
+				"a.yaml":    "include: [\"b.yaml\"]\n",
+				"b.yaml":    "include: [\"a.yaml\"]\n",
+			},
+			expected: []string{"include cycle:", "a.yaml -> ", "b.yaml -> ", "a.yaml"},
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"main.yaml": "include: [\"missing.yaml\"]\n",
+			},
+			expected: []string{`include "missing.yaml" did not match any file`},
+		},
+		{
+			name: "unknown dependency",
+			files: map[string]string{
+				"main.yaml":  "include: [\"other.yaml\"]\n",
+				"other.yaml": "tests:\n  - name: \"A\"\n    url: \"/a\"\n    depends_on: [\"Missing\"]\n",
+			},
+			expected: []string{`test "A" depends on unknown test "Missing"`, "other.yaml:2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeSuiteFiles(t, tt.files)
+
+			_, err := ParseTestSuite(filepath.Join(dir, "main.yaml"))
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected error to contain %q, got %q", expected, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestParseTestSuite_IncludeDataRelativeToIncludedFile(t *testing.T) {
+	dir := writeSuiteFiles(t, map[string]string{
+		"main.yaml": "include: [\"users/users.yaml\"]\n",
+		"users/users.yaml": `
+tests:
+  - name: "Get User ${id}"
+    url: "/users/${id}"
+    data: "users.csv"
+`,
+		"users/users.csv": "id\n1\n2\n",
+	})
+
+	suite, err := ParseTestSuite(filepath.Join(dir, "main.yaml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(suite.Tests) != 2 || InterpolateVariables(suite.Tests[1].URL, suite.Tests[1].variables) != "/users/2" {
+		t.Errorf("Expected 2 tests loaded from users/users.csv, got %v", suite.Tests)
+	}
+}
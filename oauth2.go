@@ -0,0 +1,180 @@
+package goresttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// oauth2Token is an access token issued by an OAuth2 token endpoint
+type oauth2Token struct {
+	accessToken  string
+	refreshToken string
+	// expiry is when the token is refreshed, ahead of its actual
+	// expiration. It is zero for tokens without a lifetime.
+	expiry time.Time
+}
+
+func (t *oauth2Token) expired() bool {
+	return !t.expiry.IsZero() && !time.Now().Before(t.expiry)
+}
+
+// ResetTokens discards the cached OAuth2 access tokens
+func (c *HTTPClient) ResetTokens() {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+	c.tokens = nil
+}
+
+// doOAuth2 sends req with an OAuth2 access token. If the server rejects the
+// token with 401, req is sent once more with a new token. It returns the
+// tokens that were sent.
+func (c *HTTPClient) doOAuth2(client *http.Client, req *http.Request, auth *Auth) (*http.Response, []string, error) {
+	token, err := c.oauth2Token(req.Context(), auth, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get oauth2 token: %w", err)
+	}
+	sent := []string{token.accessToken}
+
+	req.Header.Set("Authorization", "Bearer "+token.accessToken)
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, sent, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	token, err = c.oauth2Token(req.Context(), auth, token.accessToken)
+	if err != nil {
+		return nil, sent, fmt.Errorf("failed to get oauth2 token: %w", err)
+	}
+	sent = append(sent, token.accessToken)
+
+	retry, err := cloneRequest(req)
+	if err != nil {
+		return nil, sent, err
+	}
+	retry.Header.Set("Authorization", "Bearer "+token.accessToken)
+	resp, err = client.Do(retry)
+	return resp, sent, err
+}
+
+// oauth2Token returns the cached token of auth, or requests a new one if
+// there is none, it expired or it is the rejected token. Expired tokens are
+// renewed with their refresh token when they have one.
+func (c *HTTPClient) oauth2Token(ctx context.Context, auth *Auth, rejected string) (*oauth2Token, error) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	key := strings.Join([]string{auth.TokenURL, auth.GrantType, auth.ClientID, auth.ClientSecret, auth.Username, auth.Password, auth.Scope}, "\x00")
+	cached := c.tokens[key]
+	if cached != nil && !cached.expired() && cached.accessToken != rejected {
+		return cached, nil
+	}
+
+	var token *oauth2Token
+	if cached != nil && cached.refreshToken != "" {
+		// A failed refresh falls back to a new grant
+		token, _ = c.requestToken(ctx, auth, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {cached.refreshToken},
+		})
+	}
+	if token == nil {
+		var err error
+		token, err = c.requestToken(ctx, auth, oauth2Grant(auth))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.tokens == nil {
+		c.tokens = make(map[string]*oauth2Token)
+	}
+	c.tokens[key] = token
+	return token, nil
+}
+
+// oauth2Grant returns the form parameters of the token request of auth
+func oauth2Grant(auth *Auth) url.Values {
+	grantType := auth.GrantType
+	if grantType == "" {
+		grantType = "client_credentials"
+	}
+
+	form := url.Values{"grant_type": {grantType}}
+	if grantType == "password" {
+		form.Set("username", auth.Username)
+		form.Set("password", auth.Password)
+	}
+	if auth.Scope != "" {
+		form.Set("scope", auth.Scope)
+	}
+	return form
+}
+
+// requestToken posts a token request to the token endpoint of auth
+func (c *HTTPClient) requestToken(ctx context.Context, auth *Auth, form url.Values) (*oauth2Token, error) {
+	if auth.TokenURL == "" {
+		return nil, fmt.Errorf("oauth2 auth requires a token_url")
+	}
+
+	switch auth.ClientAuth {
+	case "", "basic":
+	case "body":
+		form.Set("client_id", auth.ClientID)
+		form.Set("client_secret", auth.ClientSecret)
+	default:
+		return nil, fmt.Errorf("unsupported oauth2 client_auth %q: expected basic or body", auth.ClientAuth)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.buildURL(auth.TokenURL), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if auth.ClientAuth != "body" && auth.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(auth.ClientID), url.QueryEscape(auth.ClientSecret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var response struct {
+		AccessToken  string  `json:"access_token"`
+		RefreshToken string  `json:"refresh_token"`
+		ExpiresIn    float64 `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	token := &oauth2Token{accessToken: response.AccessToken, refreshToken: response.RefreshToken}
+	if response.ExpiresIn > 0 {
+		// Refresh once 90% of the lifetime has passed, so that tokens do
+		// not expire while a request is in flight
+		lifetime := time.Duration(response.ExpiresIn * float64(time.Second))
+		token.expiry = time.Now().Add(lifetime - lifetime/10)
+	}
+	return token, nil
+}
@@ -0,0 +1,187 @@
+package goresttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tokenServer is an OAuth2 token endpoint on /token that issues numbered
+// tokens, in front of /api which only accepts the latest token
+type tokenServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	issued   int
+	current  string
+	requests []map[string]string
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	t.Helper()
+
+	ts := &tokenServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.mutex.Lock()
+		defer ts.mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			request := map[string]string{}
+			for key := range r.PostForm {
+				request[key] = r.PostForm.Get(key)
+			}
+			if id, secret, ok := r.BasicAuth(); ok {
+				request["basic"] = id + ":" + secret
+			}
+			ts.requests = append(ts.requests, request)
+
+			if request["grant_type"] == "refresh_token" && request["refresh_token"] != "refresh-"+ts.current {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			ts.issued++
+			ts.current = fmt.Sprintf("token-%d", ts.issued)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  ts.current,
+				"token_type":    "Bearer",
+				"expires_in":    3600,
+				"refresh_token": "refresh-" + ts.current,
+			})
+		case "/api":
+			if r.Header.Get("Authorization") != "Bearer "+ts.current {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(ts.current))
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// revoke makes /api reject the current token
+func (ts *tokenServer) revoke() {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	ts.current = "revoked"
+}
+
+func TestTestRunner_RunTestSuite_OAuth2(t *testing.T) {
+	server := newTokenServer(t)
+
+	suite := &TestSuite{
+		Variables: map[string]interface{}{"client_secret": "s3cret"},
+		Auth: &Auth{
+			Type:         "oauth2",
+			TokenURL:     "/token",
+			ClientID:     "tests",
+			ClientSecret: "${client_secret}",
+			Scope:        "read",
+		},
+		Parallel: true,
+		Tests: []Test{
+			{Name: "A", URL: "/api", Assertions: statusOK()},
+			{Name: "B", URL: "/api", Assertions: statusOK()},
+			{Name: "C", URL: "/api", Assertions: statusOK()},
+		},
+	}
+
+	runner := NewTestRunner(server.URL)
+	results, err := runner.RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != StatusPassed {
+			t.Errorf("Expected %s to pass, got %s: %s", result.Name, result.Status, result.Error)
+		}
+	}
+
+	if len(server.requests) != 1 {
+		t.Fatalf("Expected the token to be requested once, got %d requests", len(server.requests))
+	}
+	want := map[string]string{"grant_type": "client_credentials", "scope": "read", "basic": "tests:s3cret"}
+	for key, value := range want {
+		if server.requests[0][key] != value {
+			t.Errorf("Expected token request %s %q, got %q", key, value, server.requests[0][key])
+		}
+	}
+
+	// Every run requests its own token
+	if _, err := runner.RunTestSuite(suite); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(server.requests) != 2 {
+		t.Errorf("Expected a new token for the second run, got %d requests", len(server.requests))
+	}
+}
+
+func TestHTTPClient_ExecuteRequest_OAuth2Refresh(t *testing.T) {
+	server := newTokenServer(t)
+	client := NewHTTPClient(server.URL)
+
+	test := Test{
+		Name: "API",
+		URL:  "/api",
+		Auth: &Auth{
+			Type:       "oauth2",
+			TokenURL:   server.URL + "/token",
+			GrantType:  "password",
+			Username:   "alice",
+			Password:   "pa55",
+			ClientID:   "tests",
+			ClientAuth: "body",
+		},
+	}
+
+	execute := func(expected string) {
+		t.Helper()
+		result, err := client.ExecuteRequest(test, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.StatusCode != http.StatusOK || result.Response != expected {
+			t.Errorf("Expected %s to be accepted, got status %d: %s", expected, result.StatusCode, result.Response)
+		}
+		if !containsString(result.secrets, expected) {
+			t.Errorf("Expected %s to be masked in reports, got secrets %v", expected, result.secrets)
+		}
+	}
+
+	execute("token-1")
+	if request := server.requests[0]; request["username"] != "alice" || request["password"] != "pa55" || request["client_id"] != "tests" || request["basic"] != "" {
+		t.Errorf("Unexpected password grant request: %v", request)
+	}
+
+	// A rejected token is replaced, with a new grant once the server
+	// refuses its refresh token too
+	server.revoke()
+	execute("token-2")
+	if grant := server.requests[len(server.requests)-1]["grant_type"]; grant != "password" {
+		t.Errorf("Expected a new password grant after 401, got %s", grant)
+	}
+
+	// An expired token is renewed with its refresh token
+	for _, token := range client.tokens {
+		token.expiry = time.Now().Add(-time.Second)
+	}
+	execute("token-3")
+	if grant := server.requests[len(server.requests)-1]["grant_type"]; grant != "refresh_token" {
+		t.Errorf("Expected a refresh_token grant after expiry, got %s", grant)
+	}
+	if len(server.requests) != 4 {
+		t.Errorf("Expected 4 token requests, got %d", len(server.requests))
+	}
+
+	test.Auth = &Auth{Type: "oauth2", TokenURL: "/missing"}
+	result, err := client.ExecuteRequest(test, nil)
+	if err == nil || !strings.Contains(result.Error, "failed to get oauth2 token") {
+		t.Errorf("Expected token error, got %v", err)
+	}
+}
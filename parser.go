@@ -1,59 +1,289 @@
 package goresttest
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"os"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
-// ParseTestSuite parses a YAML test configuration file and returns a TestSuite
+// ParseTestSuite parses a YAML test configuration file and returns a TestSuite.
+// Files named by the suite's include directive are merged into it. Unknown
+// fields, assertion types, operators and extractors are reported as
+// ValidationErrors.
 func ParseTestSuite(filename string) (*TestSuite, error) {
-	file, err := os.Open(filename)
+	loader := newSuiteLoader()
+	suite, err := loader.result(loader.loadFile(filename))
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
+	}
+
+	if err := prepareSuite(suite); err != nil {
+		return nil, err
 	}
-	defer file.Close()
 
-	data, err := io.ReadAll(file)
+	return suite, nil
+}
+
+// ParseTestSuiteFromString parses a YAML string and returns a TestSuite.
+// Relative include and data file paths are resolved against the working
+// directory.
+func ParseTestSuiteFromString(yamlContent string) (*TestSuite, error) {
+	loader := newSuiteLoader()
+	suite, err := loader.result(loader.load([]byte(yamlContent), "", "."))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, err
 	}
 
-	var suite TestSuite
-	if err := yaml.Unmarshal(data, &suite); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if err := prepareSuite(suite); err != nil {
+		return nil, err
 	}
 
+	return suite, nil
+}
+
+// prepareSuite applies defaults and templates, expands data-driven tests and
+// validates the dependency graph of a freshly parsed suite.
+func prepareSuite(suite *TestSuite) error {
 	if suite.MaxWorkers <= 0 {
 		suite.MaxWorkers = 10
 	}
 
-	return &suite, nil
+	if err := resolveTemplates(suite); err != nil {
+		return err
+	}
+
+	for _, tests := range []*[]Test{&suite.Setup, &suite.Tests, &suite.Teardown} {
+		expanded, err := expandDataDrivenTests(*tests)
+		if err != nil {
+			return err
+		}
+		*tests = expanded
+	}
+
+	return ValidateSuite(suite)
 }
 
-// ParseTestSuiteFromString parses a YAML string and returns a TestSuite
-func ParseTestSuiteFromString(yamlContent string) (*TestSuite, error) {
-	var suite TestSuite
-	if err := yaml.Unmarshal([]byte(yamlContent), &suite); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+// InterpolateVariables replaces variable placeholders in text with their
+// values. ${env:NAME} is replaced with the environment variable NAME, and
+// ${name(args)} with the result of a built-in function such as uuid().
+// ${name:-default} is replaced with default if name is undefined or empty,
+// and $${...} is replaced with the literal text ${...}. String values are
+// inserted as they are and all other values as JSON. Placeholders without a
+// value, and function calls that fail, are left unchanged.
+func InterpolateVariables(text string, variables map[string]interface{}) string {
+	result, _ := interpolateStrict(text, variables)
+	return result
+}
+
+// InterpolateJSON interpolates a JSON document like InterpolateVariables,
+// but keeps it valid JSON. Inside string literals values are escaped, and
+// elsewhere they are encoded as JSON, so {"tags": ${tags}} inserts a list
+// variable as an array. String values that are valid JSON on their own, such
+// as "42", are inserted as they are.
+func InterpolateJSON(text string, variables map[string]interface{}) string {
+	result, _ := interpolateJSONStrict(text, variables)
+	return result
+}
+
+// interpolateStrict interpolates text like InterpolateVariables and also
+// returns the placeholders it left unchanged
+func interpolateStrict(text string, variables map[string]interface{}) (string, []string) {
+	return interpolateValues(text, false, variables, func(value interface{}, _ bool) string {
+		return formatVariable(value)
+	})
+}
+
+// interpolateJSONStrict interpolates text like InterpolateJSON and also
+// returns the placeholders it left unchanged
+func interpolateJSONStrict(text string, variables map[string]interface{}) (string, []string) {
+	return interpolateValues(text, true, variables, func(value interface{}, inString bool) string {
+		if inString {
+			encoded, err := marshalJSON(formatVariable(value))
+			if err != nil {
+				return formatVariable(value)
+			}
+			return string(encoded[1 : len(encoded)-1])
+		}
+		if s, ok := value.(string); ok && json.Valid([]byte(s)) {
+			return s
+		}
+		encoded, err := marshalJSON(value)
+		if err != nil {
+			return formatVariable(value)
+		}
+		return string(encoded)
+	})
+}
+
+// interpolateValues replaces the placeholders of text with their values
+// formatted by format, and returns the placeholders it could not resolve
+func interpolateValues(text string, trackJSON bool, variables map[string]interface{}, format func(value interface{}, inString bool) string) (string, []string) {
+	var unresolved []string
+	result := interpolation{
+		trackJSON: trackJSON,
+		resolve: func(expression string, inString bool) (string, bool) {
+			value, err := resolvePlaceholder(expression, variables)
+			if err == nil {
+				return format(value, inString), true
+			}
+
+			placeholder := "${" + expression + "}"
+			if !errors.Is(err, errUndefinedVariable) {
+				placeholder = fmt.Sprintf("%s (%v)", placeholder, err)
+			}
+			unresolved = append(unresolved, placeholder)
+			return "", false
+		},
+	}.apply(text)
+	return result, unresolved
+}
+
+var errUndefinedVariable = errors.New("undefined variable")
+
+// resolvePlaceholder returns the value of the expression of a placeholder,
+// which is a function call or a variable name with an optional default
+func resolvePlaceholder(expression string, variables map[string]interface{}) (interface{}, error) {
+	if call, ok := parseFunctionCall(expression); ok {
+		return call.evaluate(variables)
 	}
 
-	if suite.MaxWorkers <= 0 {
-		suite.MaxWorkers = 10
+	name, fallback, hasDefault := strings.Cut(expression, ":-")
+	if value, exists := lookupVariable(name, variables); exists && (!hasDefault || !isEmptyVariable(value)) {
+		return value, nil
 	}
+	if !hasDefault {
+		return nil, errUndefinedVariable
+	}
+
+	value, unresolved := interpolateStrict(fallback, variables)
+	if len(unresolved) > 0 {
+		return nil, fmt.Errorf("default value uses %s", strings.Join(unresolved, ", "))
+	}
+	return value, nil
+}
+
+// interpolateRowVariables replaces the placeholders of variables defined by
+// a data-driven row, including inside function arguments and default values,
+// but leaves function calls, escapes and every other placeholder to be
+// interpolated when the test runs.
+func interpolateRowVariables(text string, row map[string]string) string {
+	return interpolation{
+		keepEscapes: true,
+		resolve: func(expression string, _ bool) (string, bool) {
+			if call, ok := parseFunctionCall(expression); ok {
+				substituted, changed := call.substitute(row)
+				return "${" + substituted + "}", changed
+			}
 
-	return &suite, nil
+			name, fallback, hasDefault := strings.Cut(expression, ":-")
+			if value, exists := row[name]; exists && (value != "" || !hasDefault) {
+				return value, true
+			}
+			if hasDefault {
+				substituted := interpolateRowVariables(fallback, row)
+				return "${" + name + ":-" + substituted + "}", substituted != fallback
+			}
+			return "", false
+		},
+	}.apply(text)
 }
 
-// InterpolateVariables replaces variable placeholders in text with their values
-func InterpolateVariables(text string, variables map[string]string) string {
-	result := text
-	for key, value := range variables {
-		placeholder := fmt.Sprintf("${%s}", key)
-		result = strings.ReplaceAll(result, placeholder, value)
+// interpolation replaces every ${...} placeholder in a text with the value
+// resolve returns for its expression. Placeholders that resolve does not
+// know are left unchanged.
+type interpolation struct {
+	resolve func(expression string, inString bool) (string, bool)
+	// keepEscapes leaves escaped placeholders, $${...}, in place instead of
+	// replacing them with ${...}
+	keepEscapes bool
+	// trackJSON tells resolve whether a placeholder is inside a JSON string
+	// literal
+	trackJSON bool
+}
+
+func (in interpolation) apply(text string) string {
+	var result strings.Builder
+	inString := false
+	for {
+		start := strings.Index(text, "${")
+		if start < 0 {
+			break
+		}
+		end := placeholderEnd(text[start+2:])
+		if end < 0 {
+			break
+		}
+		end += start + 2
+
+		if in.trackJSON {
+			inString = scanJSONStrings(text[:start], inString)
+		}
+
+		if start > 0 && text[start-1] == '$' {
+			if in.keepEscapes {
+				result.WriteString(text[:end+1])
+			} else {
+				result.WriteString(text[:start-1])
+				result.WriteString(text[start : end+1])
+			}
+		} else {
+			result.WriteString(text[:start])
+			if value, ok := in.resolve(text[start+2:end], inString); ok {
+				result.WriteString(value)
+			} else {
+				result.WriteString(text[start : end+1])
+			}
+		}
+		text = text[end+1:]
 	}
-	return result
-}
\ No newline at end of file
+	result.WriteString(text)
+	return result.String()
+}
+
+// scanJSONStrings returns whether the end of text is inside a JSON string
+// literal, given whether its start is
+func scanJSONStrings(text string, inString bool) bool {
+	for i := 0; i < len(text); i++ {
+		switch {
+		case inString && text[i] == '\\':
+			i++
+		case text[i] == '"':
+			inString = !inString
+		}
+	}
+	return inString
+}
+
+// placeholderEnd returns the index of the brace closing a placeholder whose
+// expression starts text, or -1 if it is not closed. Braces nested in
+// function arguments, and braces inside their quoted strings, are skipped.
+func placeholderEnd(text string) int {
+	depth, parens := 0, 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && parens > 0:
+			quote = c
+		case c == '(':
+			parens++
+		case c == ')':
+			parens--
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
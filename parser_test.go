@@ -0,0 +1,34 @@
+package goresttest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTestSuiteFromString_Timeouts(t *testing.T) {
+	yamlContent := `
+name: "Timeouts"
+timeout: 5s
+tests:
+  - name: "Slow Report"
+    url: "/reports"
+    timeout: 1m30s
+  - name: "Health"
+    url: "/health"
+`
+
+	suite, err := ParseTestSuiteFromString(yamlContent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if suite.Timeout != 5*time.Second {
+		t.Errorf("Expected suite timeout 5s, got %v", suite.Timeout)
+	}
+	if suite.Tests[0].Timeout != 90*time.Second {
+		t.Errorf("Expected test timeout 1m30s, got %v", suite.Tests[0].Timeout)
+	}
+	if suite.Tests[1].Timeout != 0 {
+		t.Errorf("Expected no test timeout, got %v", suite.Tests[1].Timeout)
+	}
+}
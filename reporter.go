@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"html/template"
 	"os"
+	"sort"
 	"strings"
 	"time"
 )
@@ -19,7 +20,11 @@ func NewReporter() *Reporter {
 
 // PrintConsoleReport prints test results to the console
 func (r *Reporter) PrintConsoleReport(testResults []*TestResult) {
+	testResults = maskSecrets(testResults)
 	fmt.Println("=== API Test Results ===")
+	if environment := reportEnvironment(testResults); environment != "" {
+		fmt.Printf("Environment: %s\n", environment)
+	}
 	fmt.Println()
 	
 	if len(testResults) > 0 {
@@ -34,23 +39,35 @@ func (r *Reporter) printTestResults(results []*TestResult) {
 	fmt.Println(strings.Repeat("-", 80))
 	
 	for _, result := range results {
-		status := "✓ PASS"
-		if !result.Success {
-			status = "✗ FAIL"
-		}
+		status := resultStatus(result)
 		
-		fmt.Printf("%-50s %s (%v)\n", result.Name, status, result.Duration)
+		fmt.Printf("%-50s %s (%v)\n", displayName(result), statusLabel(status), result.Duration)
 		
-		if !result.Success && result.Error != "" {
+		if (status == StatusFailed || status == StatusCancelled) && result.Error != "" {
 			fmt.Printf("  Error: %s\n", result.Error)
 		}
 		
+		if status == StatusSkipped {
+			fmt.Printf("  Skipped: %s\n", result.SkipReason)
+		}
+		
+		if status == StatusNotRun {
+			fmt.Printf("  Not run: %s\n", result.SkipReason)
+		}
+		
 		if result.StatusCode > 0 {
 			fmt.Printf("  Status: %d\n", result.StatusCode)
 		}
 		
 		if len(result.Variables) > 0 {
-			fmt.Printf("  Extracted variables: %v\n", result.Variables)
+			fmt.Printf("  Extracted variables: %s\n", formatVariable(result.Variables))
+		}
+		
+		if len(result.Attempts) > 1 {
+			fmt.Printf("  Attempts: %d\n", len(result.Attempts))
+			for _, attempt := range result.Attempts {
+				fmt.Printf("    #%d %s\n", attempt.Number, formatAttempt(attempt))
+			}
 		}
 		
 		fmt.Println()
@@ -61,50 +78,218 @@ func (r *Reporter) printSummary(testResults []*TestResult) {
 	fmt.Println(strings.Repeat("=", 80))
 	
 	if len(testResults) > 0 {
-		passed := 0
-		failed := 0
-		
-		for _, result := range testResults {
-			if result.Success {
-				passed++
-			} else {
-				failed++
+		summary := summarize(testResults)
+		fmt.Printf("Tests: %d total, %d passed, %d failed, %d skipped",
+			summary.TotalTests, summary.PassedTests, summary.FailedTests, summary.SkippedTests)
+		if summary.CancelledTests > 0 {
+			fmt.Printf(", %d cancelled", summary.CancelledTests)
+		}
+		if summary.NotRunTests > 0 {
+			fmt.Printf(", %d not run", summary.NotRunTests)
+		}
+		fmt.Println()
+	}
+}
+
+// reportSummary holds the per-status test counts shown by every report
+type reportSummary struct {
+	TotalTests     int `json:"total_tests"`
+	PassedTests    int `json:"passed_tests"`
+	FailedTests    int `json:"failed_tests"`
+	SkippedTests   int `json:"skipped_tests"`
+	CancelledTests int `json:"cancelled_tests"`
+	NotRunTests    int `json:"not_run_tests"`
+}
+
+func summarize(testResults []*TestResult) reportSummary {
+	summary := reportSummary{TotalTests: len(testResults)}
+	for _, result := range testResults {
+		switch resultStatus(result) {
+		case StatusPassed:
+			summary.PassedTests++
+		case StatusSkipped:
+			summary.SkippedTests++
+		case StatusCancelled:
+			summary.CancelledTests++
+		case StatusNotRun:
+			summary.NotRunTests++
+		default:
+			summary.FailedTests++
+		}
+	}
+	return summary
+}
+
+// reportEnvironment returns the environment the results were produced in
+func reportEnvironment(testResults []*TestResult) string {
+	for _, result := range testResults {
+		if result.Environment != "" {
+			return result.Environment
+		}
+	}
+	return ""
+}
+
+// displayName returns the test name, prefixed with its phase for setup and
+// teardown tests
+func displayName(result *TestResult) string {
+	if result.Phase != "" {
+		return fmt.Sprintf("[%s] %s", result.Phase, result.Name)
+	}
+	return result.Name
+}
+
+// secretMask replaces credentials in reports
+const secretMask = "****"
+
+// maskSecrets returns copies of the results in which the credentials used by
+// the requests of any of them are replaced with secretMask
+func maskSecrets(testResults []*TestResult) []*TestResult {
+	found := make(map[string]bool)
+	collectSecrets(testResults, found)
+	if len(found) == 0 {
+		return testResults
+	}
+
+	secrets := make([]string, 0, len(found))
+	for secret := range found {
+		secrets = append(secrets, secret)
+	}
+	// Longer secrets first, so that a secret containing another one is
+	// masked as a whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, secretMask)
+	}
+	return maskResults(testResults, strings.NewReplacer(pairs...))
+}
+
+func collectSecrets(testResults []*TestResult, found map[string]bool) {
+	for _, result := range testResults {
+		for _, secret := range result.secrets {
+			if secret != "" {
+				found[secret] = true
 			}
 		}
-		
-		fmt.Printf("Tests: %d total, %d passed, %d failed\n", len(testResults), passed, failed)
+		collectSecrets(result.Iterations, found)
+	}
+}
+
+func maskResults(testResults []*TestResult, replacer *strings.Replacer) []*TestResult {
+	if testResults == nil {
+		return nil
+	}
+
+	masked := make([]*TestResult, len(testResults))
+	for i, result := range testResults {
+		copied := *result
+		copied.Response = replacer.Replace(result.Response)
+		copied.Error = replacer.Replace(result.Error)
+		copied.SkipReason = replacer.Replace(result.SkipReason)
+		if result.Headers != nil {
+			copied.Headers = make(map[string][]string, len(result.Headers))
+			for key, values := range result.Headers {
+				for _, value := range values {
+					copied.Headers[key] = append(copied.Headers[key], replacer.Replace(value))
+				}
+			}
+		}
+		if result.Variables != nil {
+			copied.Variables = maskValue(result.Variables, replacer).(map[string]interface{})
+		}
+		if result.Attempts != nil {
+			copied.Attempts = make([]Attempt, len(result.Attempts))
+			for j, attempt := range result.Attempts {
+				attempt.Error = replacer.Replace(attempt.Error)
+				copied.Attempts[j] = attempt
+			}
+		}
+		copied.Iterations = maskResults(result.Iterations, replacer)
+		masked[i] = &copied
+	}
+	return masked
+}
+
+// maskValue masks the strings of a variable value, including those nested in
+// objects and arrays
+func maskValue(value interface{}, replacer *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case string:
+		return replacer.Replace(v)
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			masked[key] = maskValue(item, replacer)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = maskValue(item, replacer)
+		}
+		return masked
+	default:
+		return value
+	}
+}
+
+// formatAttempt describes the outcome of a single attempt
+func formatAttempt(attempt Attempt) string {
+	outcome := "ok"
+	if !attempt.Success {
+		outcome = attempt.Error
+	}
+	if attempt.StatusCode > 0 {
+		return fmt.Sprintf("status %d (%v): %s", attempt.StatusCode, attempt.Duration, outcome)
+	}
+	return fmt.Sprintf("(%v): %s", attempt.Duration, outcome)
+}
+
+// statusLabel returns the console label for a test status
+func statusLabel(status TestStatus) string {
+	switch status {
+	case StatusPassed:
+		return "✓ PASS"
+	case StatusSkipped:
+		return "○ SKIP"
+	case StatusCancelled:
+		return "⊘ CANCELLED"
+	case StatusNotRun:
+		return "- NOT RUN"
+	default:
+		return "✗ FAIL"
+	}
+}
+
+// statusClass returns the CSS class used by the HTML report for a test status
+func statusClass(status TestStatus) string {
+	switch status {
+	case StatusPassed:
+		return "pass"
+	case StatusSkipped, StatusCancelled, StatusNotRun:
+		return "skip"
+	default:
+		return "fail"
 	}
 }
 
 // GenerateJSONReport generates a JSON report file
 func (r *Reporter) GenerateJSONReport(testResults []*TestResult, filename string) error {
+	testResults = maskSecrets(testResults)
 	report := struct {
-		Timestamp time.Time     `json:"timestamp"`
-		Tests     []*TestResult `json:"tests"`
-		Summary   struct {
-			TotalTests  int `json:"total_tests"`
-			PassedTests int `json:"passed_tests"`
-			FailedTests int `json:"failed_tests"`
-		} `json:"summary"`
+		Timestamp   time.Time     `json:"timestamp"`
+		Environment string        `json:"environment,omitempty"`
+		Tests       []*TestResult `json:"tests"`
+		Summary     reportSummary `json:"summary"`
 	}{
-		Timestamp: time.Now(),
-		Tests:     testResults,
+		Timestamp:   time.Now(),
+		Environment: reportEnvironment(testResults),
+		Tests:       testResults,
+		Summary:     summarize(testResults),
 	}
 	
-	passed := 0
-	failed := 0
-	for _, result := range testResults {
-		if result.Success {
-			passed++
-		} else {
-			failed++
-		}
-	}
-	
-	report.Summary.TotalTests = len(testResults)
-	report.Summary.PassedTests = passed
-	report.Summary.FailedTests = failed
-	
 	data, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON report: %w", err)
@@ -115,6 +300,7 @@ func (r *Reporter) GenerateJSONReport(testResults []*TestResult, filename string
 
 // GenerateHTMLReport generates an HTML report file
 func (r *Reporter) GenerateHTMLReport(testResults []*TestResult, filename string) error {
+	testResults = maskSecrets(testResults)
 	tmpl := `<!DOCTYPE html>
 <html>
 <head>
@@ -130,9 +316,11 @@ func (r *Reporter) GenerateHTMLReport(testResults []*TestResult, filename string
         .test-body { padding: 10px; display: none; }
         .pass { border-left: 4px solid #4caf50; }
         .fail { border-left: 4px solid #f44336; }
+        .skip { border-left: 4px solid #9e9e9e; }
         .status { font-weight: bold; }
         .pass .status { color: #4caf50; }
         .fail .status { color: #f44336; }
+        .skip .status { color: #9e9e9e; }
         .benchmarks table { width: 100%; border-collapse: collapse; }
         .benchmarks th, .benchmarks td { border: 1px solid #ddd; padding: 8px; text-align: left; }
         .benchmarks th { background: #f5f5f5; }
@@ -149,6 +337,7 @@ func (r *Reporter) GenerateHTMLReport(testResults []*TestResult, filename string
     <div class="header">
         <h1>API Test Report</h1>
         <p>Generated: {{.Timestamp}}</p>
+        {{if .Environment}}<p>Environment: {{.Environment}}</p>{{end}}
     </div>
 
     <div class="summary">
@@ -157,6 +346,9 @@ func (r *Reporter) GenerateHTMLReport(testResults []*TestResult, filename string
             <p>Total: {{.Summary.TotalTests}}</p>
             <p>Passed: {{.Summary.PassedTests}}</p>
             <p>Failed: {{.Summary.FailedTests}}</p>
+            <p>Skipped: {{.Summary.SkippedTests}}</p>
+            {{if .Summary.CancelledTests}}<p>Cancelled: {{.Summary.CancelledTests}}</p>{{end}}
+            {{if .Summary.NotRunTests}}<p>Not run: {{.Summary.NotRunTests}}</p>{{end}}
         </div>
     </div>
 
@@ -164,19 +356,31 @@ func (r *Reporter) GenerateHTMLReport(testResults []*TestResult, filename string
     <div class="tests">
         <h2>Test Results</h2>
         {{range .Tests}}
-        <div class="test {{if .Success}}pass{{else}}fail{{end}}">
+        {{$status := status .}}
+        <div class="test {{statusClass $status}}">
             <div class="test-header" onclick="toggleTest(this)">
-                <span class="status">{{if .Success}}✓ PASS{{else}}✗ FAIL{{end}}</span>
-                <strong>{{.Name}}</strong>
+                <span class="status">{{statusLabel $status}}</span>
+                <strong>{{displayName .}}</strong>
                 <span style="float: right;">{{.Duration}} | Status: {{.StatusCode}}</span>
             </div>
             <div class="test-body">
-                {{if not .Success}}
+                {{if or (eq $status "failed") (eq $status "cancelled")}}
                 <p><strong>Error:</strong> {{.Error}}</p>
+                {{end}}
+                {{if eq $status "skipped"}}
+                <p><strong>Skipped:</strong> {{.SkipReason}}</p>
+                {{end}}
+                {{if eq $status "not_run"}}
+                <p><strong>Not run:</strong> {{.SkipReason}}</p>
+                {{end}}
+                {{if gt (len .Attempts) 1}}
+                <p><strong>Attempts:</strong></p>
+                <pre>{{range .Attempts}}#{{.Number}} {{formatAttempt .}}
+{{end}}</pre>
                 {{end}}
                 {{if .Variables}}
                 <p><strong>Extracted Variables:</strong></p>
-                <pre>{{range $key, $value := .Variables}}{{$key}}: {{$value}}
+                <pre>{{range $key, $value := .Variables}}{{$key}}: {{formatVariable $value}}
 {{end}}</pre>
                 {{end}}
                 <p><strong>Response:</strong></p>
@@ -191,33 +395,27 @@ func (r *Reporter) GenerateHTMLReport(testResults []*TestResult, filename string
 </html>`
 
 	report := struct {
-		Timestamp string        `json:"timestamp"`
-		Tests     []*TestResult `json:"tests"`
-		Summary   struct {
-			TotalTests  int `json:"total_tests"`
-			PassedTests int `json:"passed_tests"`
-			FailedTests int `json:"failed_tests"`
-		} `json:"summary"`
+		Timestamp   string        `json:"timestamp"`
+		Environment string        `json:"environment"`
+		Tests       []*TestResult `json:"tests"`
+		Summary     reportSummary `json:"summary"`
 	}{
-		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
-		Tests:     testResults,
+		Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
+		Environment: reportEnvironment(testResults),
+		Tests:       testResults,
+		Summary:     summarize(testResults),
 	}
 	
-	passed := 0
-	failed := 0
-	for _, result := range testResults {
-		if result.Success {
-			passed++
-		} else {
-			failed++
-		}
+	funcs := template.FuncMap{
+		"status":         resultStatus,
+		"statusLabel":    statusLabel,
+		"statusClass":    statusClass,
+		"formatAttempt":  formatAttempt,
+		"displayName":    displayName,
+		"formatVariable": formatVariable,
 	}
 	
-	report.Summary.TotalTests = len(testResults)
-	report.Summary.PassedTests = passed
-	report.Summary.FailedTests = failed
-	
-	t, err := template.New("report").Parse(tmpl)
+	t, err := template.New("report").Funcs(funcs).Parse(tmpl)
 	if err != nil {
 		return fmt.Errorf("failed to parse HTML template: %w", err)
 	}
@@ -0,0 +1,88 @@
+package goresttest
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultRetryDelay = time.Second
+
+var defaultRetryStatusCodes = []int{502, 503, 504}
+
+// attempts returns the maximum number of attempts, which is at least one
+func (p *RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry reports whether the outcome of an attempt is retryable
+func (p *RetryPolicy) shouldRetry(result *TestResult, err error) bool {
+	statusCodes := p.StatusCodes
+	onError := p.OnError
+	if len(statusCodes) == 0 && !onError && !p.UntilAssertionsPass {
+		statusCodes = defaultRetryStatusCodes
+		onError = true
+	}
+
+	if result.StatusCode == 0 {
+		var unresolved *UnresolvedPlaceholdersError
+		return err != nil && onError && !errors.As(err, &unresolved)
+	}
+
+	for _, code := range statusCodes {
+		if result.StatusCode == code {
+			return true
+		}
+	}
+
+	return p.UntilAssertionsPass && !result.Success
+}
+
+// delay returns how long to wait after the given attempt before the next one
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Delay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	if p.Backoff == "exponential" {
+		for i := 1; i < attempt; i++ {
+			delay *= 2
+			if p.MaxDelay > 0 && delay >= p.MaxDelay {
+				break
+			}
+		}
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+func newAttempt(number int, result *TestResult) Attempt {
+	return Attempt{
+		Number:     number,
+		Success:    result.Success,
+		StatusCode: result.StatusCode,
+		Duration:   result.Duration,
+		TimedOut:   result.TimedOut,
+		Error:      result.Error,
+	}
+}
+
+// sleepContext waits for d and returns false if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,146 @@
+package goresttest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{name: "default delay", policy: RetryPolicy{}, attempt: 3, want: time.Second},
+		{name: "fixed", policy: RetryPolicy{Delay: 100 * time.Millisecond}, attempt: 3, want: 100 * time.Millisecond},
+		{name: "exponential", policy: RetryPolicy{Backoff: "exponential", Delay: 100 * time.Millisecond}, attempt: 3, want: 400 * time.Millisecond},
+		{name: "exponential capped", policy: RetryPolicy{Backoff: "exponential", Delay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond}, attempt: 3, want: 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.delay(tt.attempt); got != tt.want {
+				t.Errorf("delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		result *TestResult
+		err    bool
+		want   bool
+	}{
+		{name: "default retries 503", policy: RetryPolicy{}, result: &TestResult{StatusCode: 503}, want: true},
+		{name: "default retries transport error", policy: RetryPolicy{}, result: &TestResult{}, err: true, want: true},
+		{name: "default ignores 404", policy: RetryPolicy{}, result: &TestResult{StatusCode: 404}, want: false},
+		{name: "configured status code", policy: RetryPolicy{StatusCodes: []int{429}}, result: &TestResult{StatusCode: 429}, want: true},
+		{name: "configured status codes only", policy: RetryPolicy{StatusCodes: []int{429}}, result: &TestResult{}, err: true, want: false},
+		{name: "until assertions pass", policy: RetryPolicy{UntilAssertionsPass: true}, result: &TestResult{StatusCode: 200}, want: true},
+		{name: "until assertions pass done", policy: RetryPolicy{UntilAssertionsPass: true}, result: &TestResult{StatusCode: 200, Success: true}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.err {
+				err = http.ErrHandlerTimeout
+			}
+			if got := tt.policy.shouldRetry(tt.result, err); got != tt.want {
+				t.Errorf("shouldRetry() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestExecutor_RetryUntilAssertionsPass(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(503)
+		case 2:
+			w.Write([]byte(`{"state": "pending"}`))
+		default:
+			w.Write([]byte(`{"state": "done"}`))
+		}
+	}))
+	defer server.Close()
+
+	suite := &TestSuite{
+		Tests: []Test{
+			{
+				Name: "Poll Job",
+				URL:  "/jobs/1",
+				Retry: &RetryPolicy{
+					MaxAttempts:         5,
+					Delay:               10 * time.Millisecond,
+					StatusCodes:         []int{503},
+					UntilAssertionsPass: true,
+				},
+				Assertions: []Assertion{
+					{Type: "status_code", Expected: 200},
+					{Type: "json_path", Path: "state", Expected: "done"},
+				},
+			},
+		},
+	}
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := results[0]
+	if result.Status != StatusPassed {
+		t.Fatalf("Expected test to pass, got %s: %s", result.Status, result.Error)
+	}
+	if len(result.Attempts) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(result.Attempts))
+	}
+	if result.Attempts[0].StatusCode != 503 || result.Attempts[0].Success {
+		t.Errorf("Unexpected first attempt: %+v", result.Attempts[0])
+	}
+	if result.Attempts[1].Success || !result.Attempts[2].Success {
+		t.Errorf("Unexpected attempts: %+v", result.Attempts)
+	}
+}
+
+func TestTestExecutor_RetryGivesUp(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(503)
+	}))
+	defer server.Close()
+
+	suite := &TestSuite{
+		Tests: []Test{
+			{
+				Name:       "Unavailable",
+				URL:        "/",
+				Retry:      &RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond},
+				Assertions: statusOK(),
+			},
+		},
+	}
+
+	results, err := NewTestExecutor(server.URL).ExecuteTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != StatusFailed {
+		t.Errorf("Expected test to fail, got %s", results[0].Status)
+	}
+	if calls != 3 || len(results[0].Attempts) != 3 {
+		t.Errorf("Expected 3 attempts, got %d calls and %d recorded attempts", calls, len(results[0].Attempts))
+	}
+}
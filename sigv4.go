@@ -0,0 +1,220 @@
+package goresttest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sigV4Algorithm identifies AWS Signature Version 4 with SHA-256
+const sigV4Algorithm = "AWS4-HMAC-SHA256"
+
+// sigV4UnsignedHeaders are left out of the signature because clients and
+// proxies may change them
+var sigV4UnsignedHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+}
+
+// awsEnvironmentDefaults fills in the credentials and region of aws_sigv4
+// auth that are not configured from the standard AWS environment variables
+func (a *Auth) awsEnvironmentDefaults() {
+	if a.Type != "aws_sigv4" {
+		return
+	}
+
+	defaults := []struct {
+		field *string
+		names []string
+	}{
+		{&a.AccessKeyID, []string{"AWS_ACCESS_KEY_ID"}},
+		{&a.SecretAccessKey, []string{"AWS_SECRET_ACCESS_KEY"}},
+		{&a.SessionToken, []string{"AWS_SESSION_TOKEN"}},
+		{&a.Region, []string{"AWS_REGION", "AWS_DEFAULT_REGION"}},
+	}
+	for _, d := range defaults {
+		for _, name := range d.names {
+			if *d.field == "" {
+				*d.field = os.Getenv(name)
+			}
+		}
+	}
+}
+
+// signSigV4 signs req, whose body is body, with AWS Signature Version 4 as
+// of now, setting its X-Amz-Date, X-Amz-Security-Token and Authorization
+// headers
+func signSigV4(req *http.Request, auth *Auth, body string, now time.Time) error {
+	var missing []string
+	for _, field := range []struct{ name, value string }{
+		{"region", auth.Region},
+		{"service", auth.Service},
+		{"access_key_id", auth.AccessKeyID},
+		{"secret_access_key", auth.SecretAccessKey},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("aws_sigv4 auth requires %s", strings.Join(missing, ", "))
+	}
+
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scope := strings.Join([]string{amzDate[:8], auth.Region, auth.Service, "aws4_request"}, "/")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Del("Authorization")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if auth.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", auth.SessionToken)
+	}
+	if auth.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := sigV4Headers(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4Path(req.URL, auth.Service),
+		sigV4Query(req.URL.RawQuery),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	key := []byte("AWS4" + auth.SecretAccessKey)
+	for _, part := range []string{amzDate[:8], auth.Region, auth.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, auth.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+// sigV4Headers returns the signed header names and the canonical headers of
+// req, which include its host
+func sigV4Headers(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string][]string{"host": {host}}
+	for name, headerValues := range req.Header {
+		name = strings.ToLower(name)
+		if !sigV4UnsignedHeaders[name] {
+			values[name] = append(values[name], headerValues...)
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		trimmed := make([]string, len(values[name]))
+		for i, value := range values[name] {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		canonical.WriteString(name + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// sigV4Path returns the canonical URI of a request. Except for S3, the path
+// is normalized and its already escaped segments are escaped once more.
+func sigV4Path(u *url.URL, service string) string {
+	escaped := u.EscapedPath()
+	if escaped == "" {
+		return "/"
+	}
+	if service == "s3" {
+		return escaped
+	}
+
+	cleaned := path.Clean(escaped)
+	if strings.HasSuffix(escaped, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	segments := strings.Split(cleaned, "/")
+	for i, segment := range segments {
+		segments[i] = sigV4Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// sigV4Query returns the canonical query string: every parameter escaped and
+// sorted by name, then value
+func sigV4Query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var params [][2]string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		params = append(params, [2]string{sigV4Escape(name), sigV4Escape(value)})
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i][0] != params[j][0] {
+			return params[i][0] < params[j][0]
+		}
+		return params[i][1] < params[j][1]
+	})
+
+	encoded := make([]string, len(params))
+	for i, param := range params {
+		encoded[i] = param[0] + "=" + param[1]
+	}
+	return strings.Join(encoded, "&")
+}
+
+// sigV4Escape percent-encodes every byte of s except the unreserved
+// characters of RFC 3986
+func sigV4Escape(s string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,151 @@
+package goresttest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The credentials and expected signatures come from the examples of the AWS
+// Signature Version 4 documentation and test suite
+func TestSignSigV4(t *testing.T) {
+	signedAt := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	auth := &Auth{
+		Type:            "aws_sigv4",
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+
+	tests := []struct {
+		name          string
+		service       string
+		url           string
+		headers       map[string]string
+		authorization string
+	}{
+		{
+			name:    "get-vanilla",
+			service: "service",
+			url:     "https://example.amazonaws.com/",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:    "iam-list-users",
+			service: "iam",
+			url:     "https://iam.amazonaws.com/?Version=2010-05-08&Action=ListUsers",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+				"SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.url, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			// User-Agent is not signed
+			req.Header.Set("User-Agent", "goresttest")
+
+			signing := *auth
+			signing.Service = tt.service
+			if err := signSigV4(req, &signing, "", signedAt); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("Expected X-Amz-Date 20150830T123600Z, got %s", got)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.authorization {
+				t.Errorf("Expected Authorization\n%s\ngot\n%s", tt.authorization, got)
+			}
+		})
+	}
+}
+
+func TestSigV4Canonicalization(t *testing.T) {
+	paths := map[string]string{
+		"":                        "/",
+		"/":                       "/",
+		"/a/./b/../c/":            "/a/c/",
+		"/documents and settings": "/documents%2520and%2520settings",
+		"/key=value~":             "/key%3Dvalue~",
+	}
+	for p, want := range paths {
+		req, _ := http.NewRequest("GET", "https://example.com"+p, nil)
+		if got := sigV4Path(req.URL, "service"); got != want {
+			t.Errorf("sigV4Path(%q): expected %q, got %q", p, want, got)
+		}
+	}
+
+	if got, want := sigV4Query("b=2&a=z&a=1&c&space=a+b&star=*"), "a=1&a=z&b=2&c=&space=a%20b&star=%2A"; got != want {
+		t.Errorf("sigV4Query: expected %q, got %q", want, got)
+	}
+}
+
+func TestHTTPClient_ExecuteRequest_SigV4(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret-key")
+	t.Setenv("AWS_SESSION_TOKEN", "env-session-token")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	test := Test{
+		Name:    "Invoke",
+		Method:  "POST",
+		URL:     "/prod/items?id=${id}",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    `{"id": ${id}}`,
+		Auth:    &Auth{Type: "aws_sigv4", Service: "execute-api"},
+	}
+
+	result, err := client.ExecuteRequest(test, map[string]interface{}{"id": 7})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	authorization := received.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDENV/") ||
+		!strings.Contains(authorization, "/eu-west-1/execute-api/aws4_request") ||
+		!strings.Contains(authorization, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Unexpected Authorization header %q", authorization)
+	}
+	if received.Header.Get("X-Amz-Security-Token") != "env-session-token" {
+		t.Errorf("Expected the session token to be sent, got %q", received.Header.Get("X-Amz-Security-Token"))
+	}
+	if !containsString(result.secrets, "env-secret-key") || !containsString(result.secrets, "env-session-token") {
+		t.Errorf("Expected the AWS credentials to be masked in reports, got secrets %v", result.secrets)
+	}
+
+	// The signature covers the request as it was sent
+	resigned, _ := http.NewRequest(received.Method, server.URL+received.URL.RequestURI(), nil)
+	for _, name := range []string{"Content-Type", "X-Amz-Date", "X-Amz-Security-Token"} {
+		resigned.Header.Set(name, received.Header.Get(name))
+	}
+	signedAt, _ := time.Parse("20060102T150405Z", received.Header.Get("X-Amz-Date"))
+	signSigV4(resigned, &Auth{Region: "eu-west-1", Service: "execute-api", AccessKeyID: "AKIDENV", SecretAccessKey: "env-secret-key", SessionToken: "env-session-token"}, `{"id": 7}`, signedAt)
+	if resigned.Header.Get("Authorization") != authorization {
+		t.Errorf("Expected signature %q, got %q", resigned.Header.Get("Authorization"), authorization)
+	}
+
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	result, err = client.ExecuteRequest(test, map[string]interface{}{"id": 7})
+	if err == nil || !strings.Contains(result.Error, "aws_sigv4 auth requires region") {
+		t.Errorf("Expected missing region error, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package goresttest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolveTemplates merges the template named by each test's extends field
+// into the test, then merges the suite defaults into every test.
+func resolveTemplates(suite *TestSuite) error {
+	resolved := make(map[string]Test, len(suite.Templates))
+
+	for _, tests := range [][]Test{suite.Setup, suite.Tests, suite.Teardown} {
+		for i := range tests {
+			if tests[i].Extends != "" {
+				template, err := resolveTemplate(suite.Templates, tests[i].Extends, resolved, nil)
+				if err != nil {
+					return fmt.Errorf("test %s: %w", describeTest(tests[i]), err)
+				}
+				tests[i] = mergeTests(template, tests[i])
+			}
+
+			if suite.Defaults != nil {
+				tests[i] = applyDefaults(*suite.Defaults, tests[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveTemplate returns the named template with the templates it extends
+// merged in. chain holds the templates being resolved to detect cycles.
+func resolveTemplate(templates map[string]Test, name string, resolved map[string]Test, chain []string) (Test, error) {
+	if template, exists := resolved[name]; exists {
+		return template, nil
+	}
+
+	for _, link := range chain {
+		if link == name {
+			return Test{}, fmt.Errorf("template cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+
+	template, exists := templates[name]
+	if !exists {
+		return Test{}, fmt.Errorf("unknown template %q", name)
+	}
+
+	if template.Extends != "" {
+		base, err := resolveTemplate(templates, template.Extends, resolved, append(chain, name))
+		if err != nil {
+			return Test{}, err
+		}
+		template = mergeTests(base, template)
+	}
+
+	resolved[name] = template
+	return template, nil
+}
+
+// mergeTests returns test with every field it leaves unset taken from base.
+// Headers and extractions are merged with test taking precedence, and
+// assertions are merged by type and path so that test can replace an
+// assertion of base.
+func mergeTests(base, test Test) Test {
+	merged := test
+	merged.Extends = ""
+
+	if merged.Method == "" {
+		merged.Method = base.Method
+	}
+	if merged.URL == "" {
+		merged.URL = base.URL
+	}
+	if merged.Body == "" && merged.BodyFile == "" {
+		merged.Body = base.Body
+		merged.BodyFile = base.BodyFile
+	}
+	if merged.Timeout == 0 {
+		merged.Timeout = base.Timeout
+	}
+	if merged.Retry == nil {
+		merged.Retry = base.Retry
+	}
+	if merged.ForEach == nil {
+		merged.ForEach = base.ForEach
+	}
+	if merged.Matrix == nil {
+		merged.Matrix = base.Matrix
+	}
+	if merged.Data == nil {
+		merged.Data = base.Data
+	}
+	if merged.SkipIf == "" {
+		merged.SkipIf = base.SkipIf
+	}
+	if merged.RunIf == "" {
+		merged.RunIf = base.RunIf
+	}
+	if merged.DependsOn == nil {
+		merged.DependsOn = base.DependsOn
+	}
+	if merged.Session == "" {
+		merged.Session = base.Session
+	}
+	if merged.Auth == nil {
+		merged.Auth = base.Auth
+	}
+
+	merged.Headers = mergeStringMaps(base.Headers, test.Headers)
+	merged.Extract = mergeStringMaps(base.Extract, test.Extract)
+	merged.Assertions = mergeAssertions(base.Assertions, test.Assertions)
+	merged.Tags = mergeTags(base.Tags, test.Tags)
+
+	return merged
+}
+
+// applyDefaults fills in the suite defaults for everything test leaves unset
+func applyDefaults(defaults TestDefaults, test Test) Test {
+	test.Headers = mergeStringMaps(defaults.Headers, test.Headers)
+	test.Assertions = mergeAssertions(defaults.Assertions, test.Assertions)
+	if test.Timeout == 0 {
+		test.Timeout = defaults.Timeout
+	}
+	if test.Retry == nil {
+		test.Retry = defaults.Retry
+	}
+	return test
+}
+
+func mergeStringMaps(base, override map[string]string) map[string]string {
+	if len(base) == 0 {
+		return override
+	}
+
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// mergeAssertions returns the assertions of base followed by those of
+// override. An assertion of override with the same type and path as one of
+// base replaces it.
+func mergeAssertions(base, override []Assertion) []Assertion {
+	if len(base) == 0 {
+		return override
+	}
+
+	merged := make([]Assertion, 0, len(base)+len(override))
+	for _, assertion := range base {
+		if !containsAssertion(override, assertion.Type, assertion.Path) {
+			merged = append(merged, assertion)
+		}
+	}
+	return append(merged, override...)
+}
+
+func containsAssertion(assertions []Assertion, assertionType, path string) bool {
+	for _, assertion := range assertions {
+		if assertion.Type == assertionType && assertion.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeTags(base, override []string) []string {
+	if len(base) == 0 {
+		return override
+	}
+
+	merged := append([]string(nil), base...)
+	for _, tag := range override {
+		if !hasAnyTag(Test{Tags: merged}, []string{tag}) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,121 @@
+package goresttest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTestSuiteFromString_DefaultsAndTemplates(t *testing.T) {
+	yamlContent := `
+name: "Templates"
+defaults:
+  timeout: 5s
+  headers:
+    Accept: "application/json"
+    Authorization: "Bearer ${token}"
+  assertions:
+    - type: "status_code"
+      expected: 200
+templates:
+  json_post:
+    method: "POST"
+    headers:
+      Content-Type: "application/json"
+    tags: ["write"]
+  create_user:
+    extends: "json_post"
+    url: "/users"
+    body: '{"name": "${name}"}'
+    assertions:
+      - type: "status_code"
+        expected: 201
+tests:
+  - name: "Create User"
+    extends: "create_user"
+    headers:
+      Accept: "application/vnd.api+json"
+    assertions:
+      - type: "json_path"
+        path: "name"
+        expected: "${name}"
+  - name: "Health"
+    url: "/health"
+    timeout: 1s
+`
+
+	suite, err := ParseTestSuiteFromString(yamlContent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	create := suite.Tests[0]
+	if create.Method != "POST" || create.URL != "/users" || create.Body != `{"name": "${name}"}` {
+		t.Errorf("Template fields not merged: %+v", create)
+	}
+	wantHeaders := map[string]string{
+		"Accept":        "application/vnd.api+json",
+		"Authorization": "Bearer ${token}",
+		"Content-Type":  "application/json",
+	}
+	if !reflect.DeepEqual(create.Headers, wantHeaders) {
+		t.Errorf("Expected headers %v, got %v", wantHeaders, create.Headers)
+	}
+	wantAssertions := []Assertion{
+		{Type: "status_code", Expected: 201},
+		{Type: "json_path", Path: "name", Expected: "${name}"},
+	}
+	if !reflect.DeepEqual(create.Assertions, wantAssertions) {
+		t.Errorf("Expected assertions %v, got %v", wantAssertions, create.Assertions)
+	}
+	if create.Timeout != 5*time.Second || !reflect.DeepEqual(create.Tags, []string{"write"}) {
+		t.Errorf("Unexpected timeout %v or tags %v", create.Timeout, create.Tags)
+	}
+
+	health := suite.Tests[1]
+	if health.Timeout != time.Second || len(health.Assertions) != 1 || health.Headers["Accept"] != "application/json" {
+		t.Errorf("Defaults not applied to plain test: %+v", health)
+	}
+}
+
+func TestParseTestSuiteFromString_TemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "unknown template",
+			yaml: `
+tests:
+  - name: "A"
+    extends: "missing"
+`,
+			wantErr: `unknown template "missing"`,
+		},
+		{
+			name: "template cycle",
+			yaml: `
+templates:
+  a:
+    extends: "b"
+  b:
+    extends: "a"
+tests:
+  - name: "A"
+    extends: "a"
+`,
+			wantErr: "template cycle: a -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTestSuiteFromString(tt.yaml)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
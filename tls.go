@@ -0,0 +1,110 @@
+package goresttest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// tlsVersions maps the TLS versions accepted by min_version and tls_version
+// assertions to their crypto/tls constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion parses a TLS version such as "1.2" or "TLS 1.2"
+func parseTLSVersion(version string) (uint16, error) {
+	trimmed := strings.TrimSpace(version)
+	if len(trimmed) > 3 && strings.EqualFold(trimmed[:3], "tls") {
+		trimmed = strings.TrimSpace(trimmed[3:])
+	}
+	if value, known := tlsVersions[trimmed]; known {
+		return value, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q: expected 1.0, 1.1, 1.2 or 1.3", version)
+}
+
+// interpolate returns a copy of the settings with fn applied to the file
+// paths and server name
+func (t *TLSConfig) interpolate(fn func(string) string) *TLSConfig {
+	return &TLSConfig{
+		CACert:             fn(t.CACert),
+		ClientCert:         fn(t.ClientCert),
+		ClientKey:          fn(t.ClientKey),
+		ServerName:         fn(t.ServerName),
+		MinVersion:         fn(t.MinVersion),
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+}
+
+// build loads the certificates of the settings into a tls.Config
+func (t *TLSConfig) build() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.MinVersion != "" {
+		version, err := parseTLSVersion(t.MinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_version: %w", err)
+		}
+		config.MinVersion = version
+	}
+
+	if t.CACert != "" {
+		bundle, err := os.ReadFile(t.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		// The bundle is trusted in addition to the system roots
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", t.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if t.ClientCert != "" || t.ClientKey != "" {
+		if t.ClientCert == "" || t.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		certificate, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// SetTLSConfig configures the TLS connections of the client. A nil config
+// restores the default transport.
+func (c *HTTPClient) SetTLSConfig(config *TLSConfig) error {
+	var transport http.RoundTripper
+	if config != nil {
+		tlsConfig, err := config.build()
+		if err != nil {
+			return err
+		}
+		custom := http.DefaultTransport.(*http.Transport).Clone()
+		custom.TLSClientConfig = tlsConfig
+		transport = custom
+	}
+
+	// Connections of the previous transport were set up with the previous
+	// settings
+	c.client.CloseIdleConnections()
+	c.client.Transport = transport
+	return nil
+}
@@ -0,0 +1,226 @@
+package goresttest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePEM writes a PEM block of the given type to a file in dir
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// mtlsServer starts a TLS server that requires a client certificate signed by
+// a test CA, and returns the paths of its CA bundle and of a client
+// certificate and key
+func mtlsServer(t *testing.T) (server *httptest.Server, caCert, clientCert, clientKey string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Client CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	clientDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "goresttest"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create client certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caCert = writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	clientCert = writePEM(t, dir, "client.pem", "CERTIFICATE", clientDER)
+	clientKey = writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+	return server, caCert, clientCert, clientKey
+}
+
+func TestTestRunner_RunTestSuite_MutualTLS(t *testing.T) {
+	server, caCert, clientCert, clientKey := mtlsServer(t)
+	dir := filepath.Dir(caCert)
+
+	suiteFile := filepath.Join(dir, "suite.yaml")
+	content := `
+base_url: "` + server.URL + `"
+variables:
+  key_file: "client-key.pem"
+tls:
+  ca_cert: "ca.pem"
+  client_cert: "client.pem"
+  client_key: "` + filepath.Dir(clientKey) + `/${key_file}"
+  min_version: "1.2"
+tests:
+  - name: "Identity"
+    url: "/"
+    assertions:
+      - type: "body_contains"
+        expected: "goresttest"
+      - type: "tls_version"
+        operator: ">"
+        expected: 1.1
+      - type: "cert_expiry"
+        expected: "1h"
+      - type: "cert_expiry"
+        operator: "<"
+        expected: "36500d"
+`
+	if err := os.WriteFile(suiteFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write suite: %v", err)
+	}
+
+	suite, err := ParseTestSuite(suiteFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if suite.TLS.CACert != caCert || suite.TLS.ClientCert != clientCert {
+		t.Errorf("Expected paths relative to the suite file, got %+v", suite.TLS)
+	}
+
+	runner := NewTestRunner(server.URL)
+	results, err := runner.RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Status != StatusPassed {
+		t.Fatalf("Expected the test to pass, got %s: %s", results[0].Status, results[0].Error)
+	}
+	if results[0].TLSVersion != "TLS 1.3" || results[0].CertificateExpiry.IsZero() {
+		t.Errorf("Expected TLS details in the result, got %q and %v", results[0].TLSVersion, results[0].CertificateExpiry)
+	}
+
+	// Without the client certificate the handshake fails, and the TLS
+	// settings of the previous run are not kept
+	suite.TLS = &TLSConfig{CACert: caCert}
+	results, _ = runner.RunTestSuite(suite)
+	if results[0].Status != StatusFailed {
+		t.Errorf("Expected the test to fail without a client certificate, got %s", results[0].Status)
+	}
+	suite.TLS = nil
+	results, _ = runner.RunTestSuite(suite)
+	if !strings.Contains(results[0].Error, "certificate") {
+		t.Errorf("Expected an unknown authority error, got %q", results[0].Error)
+	}
+}
+
+func TestHTTPClient_SetTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	caCert := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	tests := []struct {
+		name    string
+		config  *TLSConfig
+		wantErr string
+	}{
+		{name: "default", config: nil, wantErr: "certificate"},
+		{name: "insecure", config: &TLSConfig{InsecureSkipVerify: true}},
+		{name: "ca", config: &TLSConfig{CACert: caCert}},
+		{name: "server name", config: &TLSConfig{CACert: caCert, ServerName: "example.com"}},
+		{name: "wrong server name", config: &TLSConfig{CACert: caCert, ServerName: "other.test"}, wantErr: "other.test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHTTPClient(server.URL)
+			if err := client.SetTLSConfig(tt.config); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result, err := client.ExecuteRequest(Test{Name: tt.name, URL: "/"}, nil)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(result.Error, tt.wantErr)) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	invalid := []struct {
+		config  *TLSConfig
+		wantErr string
+	}{
+		{&TLSConfig{MinVersion: "1.4"}, "invalid min_version"},
+		{&TLSConfig{CACert: "/missing/ca.pem"}, "failed to read CA bundle"},
+		{&TLSConfig{ClientCert: "client.pem"}, "client_cert and client_key must be set together"},
+	}
+	for _, tt := range invalid {
+		err := NewHTTPClient(server.URL).SetTLSConfig(tt.config)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+		}
+	}
+}
+
+func TestAssertionEngine_TLSAssertions(t *testing.T) {
+	engine := NewAssertionEngine()
+	result := &TestResult{TLSVersion: "TLS 1.2", CertificateExpiry: time.Now().Add(10 * 24 * time.Hour)}
+
+	tests := []struct {
+		assertion Assertion
+		passes    bool
+	}{
+		{Assertion{Type: "tls_version", Expected: 1.2}, true},
+		{Assertion{Type: "tls_version", Expected: "TLS 1.2"}, true},
+		{Assertion{Type: "tls_version", Operator: ">", Expected: "1.2"}, false},
+		{Assertion{Type: "tls_version", Operator: "<", Expected: "1.3"}, true},
+		{Assertion{Type: "cert_expiry", Expected: "7d"}, true},
+		{Assertion{Type: "cert_expiry", Expected: "30d"}, false},
+		{Assertion{Type: "cert_expiry", Operator: "<", Expected: "720h"}, true},
+	}
+
+	for _, tt := range tests {
+		errs := engine.RunAssertions(result, []Assertion{tt.assertion}, nil)
+		if passed := len(errs) == 0; passed != tt.passes {
+			t.Errorf("%s %s %v: expected pass %t, got errors %v", tt.assertion.Type, tt.assertion.Operator, tt.assertion.Expected, tt.passes, errs)
+		}
+	}
+
+	if errs := engine.RunAssertions(&TestResult{}, []Assertion{{Type: "tls_version", Expected: "1.2"}}, nil); len(errs) == 0 || !strings.Contains(errs[0], "did not use TLS") {
+		t.Errorf("Expected plain HTTP to fail tls_version, got %v", errs)
+	}
+}
@@ -4,15 +4,61 @@ import "time"
 
 type TestSuite struct {
 	Name        string            `yaml:"name"`
+	// Include lists suite files, or glob patterns, relative to this file
+	// whose tests, variables and templates are merged into this suite
+	Include     []string          `yaml:"include"`
 	BaseURL     string            `yaml:"base_url"`
-	Variables   map[string]string `yaml:"variables"`
+	// Variables keep their YAML type, so numbers, booleans, lists and maps
+	// can be inserted into JSON bodies and compared with their native type
+	Variables   map[string]interface{} `yaml:"variables"`
 	Tests       []Test            `yaml:"tests"`
+	// Setup tests run sequentially before all other tests; the variables
+	// they extract are available to every test
+	Setup       []Test            `yaml:"setup"`
+	// Teardown tests run sequentially after all other tests, even if they
+	// failed or the run was cancelled
+	Teardown    []Test            `yaml:"teardown"`
 	Parallel    bool              `yaml:"parallel"`
 	MaxWorkers  int               `yaml:"max_workers"`
+	// Defaults are merged into every test
+	Defaults    *TestDefaults     `yaml:"defaults"`
+	// Templates are named partial tests that tests can extend
+	Templates   map[string]Test   `yaml:"templates"`
+	Timeout     time.Duration     `yaml:"timeout"`
+	// FailFast stops starting new tests after the first failure
+	FailFast    bool              `yaml:"fail_fast"`
+	// MaxFailures stops starting new tests once this many tests have failed
+	MaxFailures int               `yaml:"max_failures"`
+	// StrictVariables fails tests whose request uses a placeholder without
+	// a value before the request is sent
+	StrictVariables bool `yaml:"strict_variables"`
+	// Cookies keeps the cookies set by responses in a cookie jar shared by
+	// every test that does not name a session
+	Cookies bool `yaml:"cookies"`
+	// Auth authenticates the requests of every test that does not set its own
+	Auth *Auth `yaml:"auth"`
+	// TLS configures the TLS connections of every request
+	TLS *TLSConfig `yaml:"tls"`
+	// Environments are named profiles, such as local or staging, that can be
+	// selected with ApplyEnvironment
+	Environments map[string]Environment `yaml:"environments"`
+	// Environment is the name of the selected environment, if any
+	Environment string `yaml:"-"`
+}
+
+// Environment overrides the base URL and variables of a suite, and adds
+// headers to every test, when it is selected
+type Environment struct {
+	BaseURL   string            `yaml:"base_url"`
+	Variables map[string]interface{} `yaml:"variables"`
+	Headers   map[string]string      `yaml:"headers"`
 }
 
 type Test struct {
 	Name        string            `yaml:"name"`
+	// Extends names a template from the suite's templates that provides
+	// every field this test leaves unset
+	Extends     string            `yaml:"extends"`
 	Method      string            `yaml:"method"`
 	URL         string            `yaml:"url"`
 	Headers     map[string]string `yaml:"headers"`
@@ -22,6 +68,140 @@ type Test struct {
 	Assertions  []Assertion       `yaml:"assertions"`
 	Extract     map[string]string `yaml:"extract"`
 	DependsOn   []string          `yaml:"depends_on"`
+	Tags        []string          `yaml:"tags"`
+	ForEach     *ForEach          `yaml:"for_each"`
+	// SkipIf and RunIf are conditions evaluated against the test variables
+	// right before the test runs, e.g. "${feature_flag} == false"
+	SkipIf      string            `yaml:"skip_if"`
+	RunIf       string            `yaml:"run_if"`
+	// Session names a cookie jar shared by the tests of the same session,
+	// e.g. "admin" and "user", independently of the suite's cookies setting
+	Session     string            `yaml:"session"`
+	// Auth authenticates the request, replacing the suite's auth
+	Auth        *Auth             `yaml:"auth"`
+	Retry       *RetryPolicy      `yaml:"retry"`
+	// Matrix expands the test into one instance per combination of values
+	Matrix      map[string][]string `yaml:"matrix"`
+	// Data expands the test into one instance per row
+	Data        *DataSource       `yaml:"data"`
+
+	source sourcePos
+	// variables are the row variables of a data-driven test instance
+	variables map[string]interface{}
+}
+
+// TestDefaults holds settings merged into every test of a suite. Headers are
+// merged with the test's headers, with the test taking precedence, and
+// assertions are added unless the test has one with the same type and path.
+type TestDefaults struct {
+	Headers    map[string]string `yaml:"headers"`
+	Timeout    time.Duration     `yaml:"timeout"`
+	Assertions []Assertion       `yaml:"assertions"`
+	Retry      *RetryPolicy      `yaml:"retry"`
+}
+
+// Auth configures how a request is authenticated. Every field may use
+// placeholders, which are interpolated before the request is sent.
+type Auth struct {
+	// Type is basic, bearer, api_key, digest, hmac, oauth2, aws_sigv4 or none
+	Type string `yaml:"type"`
+	// Username and Password are used by basic and digest auth, and by the
+	// oauth2 password grant
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Token is sent by bearer auth
+	Token string `yaml:"token"`
+	// Key is sent by api_key auth in the header or query parameter Name,
+	// X-API-Key by default. In is header (default) or query.
+	Key  string `yaml:"key"`
+	Name string `yaml:"name"`
+	In   string `yaml:"in"`
+	// Secret signs requests with hmac auth. The signature of Payload is
+	// sent in Header, X-Signature by default, prefixed with Prefix.
+	Secret string `yaml:"secret"`
+	// Algorithm is the hash of hmac auth: sha256 (default), sha1 or sha512
+	Algorithm string `yaml:"algorithm"`
+	// Encoding of the hmac signature: hex (default) or base64
+	Encoding string `yaml:"encoding"`
+	Header   string `yaml:"header"`
+	Prefix   string `yaml:"prefix"`
+	// Payload is the text signed by hmac auth. It can use the variables
+	// ${method}, ${path}, ${query}, ${host}, ${timestamp} and ${body}, and
+	// defaults to "${method}\n${path}\n${timestamp}\n${body}".
+	Payload string `yaml:"payload"`
+	// TimestampHeader carries ${timestamp}, X-Timestamp by default
+	TimestampHeader string `yaml:"timestamp_header"`
+	// TokenURL is the token endpoint of oauth2 auth, relative to the base
+	// URL unless absolute
+	TokenURL string `yaml:"token_url"`
+	// GrantType is client_credentials (default) or password
+	GrantType    string `yaml:"grant_type"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	Scope        string `yaml:"scope"`
+	// ClientAuth sends the client credentials with basic auth (default) or,
+	// with body, as form parameters of the token request
+	ClientAuth string `yaml:"client_auth"`
+	// Region, Service and the credentials sign requests with aws_sigv4
+	// auth. Unset credentials and region are read from AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION or
+	// AWS_DEFAULT_REGION.
+	Region          string `yaml:"region"`
+	Service         string `yaml:"service"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	SessionToken    string `yaml:"session_token"`
+}
+
+// TLSConfig configures TLS connections, e.g. to internal services with a
+// private CA or that require client certificates. Relative paths are
+// relative to the suite file.
+type TLSConfig struct {
+	// CACert is a PEM bundle of CAs trusted in addition to the system roots
+	CACert string `yaml:"ca_cert"`
+	// ClientCert and ClientKey are PEM files of the client certificate
+	// presented for mutual TLS
+	ClientCert string `yaml:"client_cert"`
+	ClientKey  string `yaml:"client_key"`
+	// ServerName overrides the host name the server certificate is
+	// verified against
+	ServerName string `yaml:"server_name"`
+	// MinVersion is the lowest accepted TLS version: 1.0, 1.1, 1.2 or 1.3
+	MinVersion         string `yaml:"min_version"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// ForEach repeats a test for every element of a JSON array held by a
+// variable, typically extracted by an earlier test
+type ForEach struct {
+	// In names the variable holding the array, either as "users" or "${users}"
+	In string `yaml:"in"`
+	// As is the loop variable bound to each element, "item" by default.
+	// The zero-based position is bound to "<as>_index".
+	As string `yaml:"as"`
+	// Field binds a field of each element, e.g. "id" or "address.city",
+	// instead of the whole element
+	Field string `yaml:"field"`
+	// MaxIterations caps the number of elements, 100 by default. Larger
+	// arrays fail the test instead of running it.
+	MaxIterations int `yaml:"max_iterations"`
+}
+
+// RetryPolicy controls how often a test is attempted before its result is
+// final. Without any retry condition set, transport errors and 502, 503 and
+// 504 responses are retried.
+type RetryPolicy struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	Backoff     string        `yaml:"backoff"` // fixed (default) or exponential
+	Delay       time.Duration `yaml:"delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+	// StatusCodes lists the response status codes that trigger a retry
+	StatusCodes []int `yaml:"status_codes"`
+	// OnError retries when the request fails without a response, including timeouts
+	OnError bool `yaml:"on_error"`
+	// UntilAssertionsPass retries until every assertion and extraction passes,
+	// which turns the test into a poll
+	UntilAssertionsPass bool `yaml:"until_assertions_pass"`
 }
 
 type Assertion struct {
@@ -31,13 +211,80 @@ type Assertion struct {
 	Operator string      `yaml:"operator"`
 }
 
+// Phases of a suite reported in TestResult.Phase
+const (
+	PhaseSetup    = "setup"
+	PhaseTeardown = "teardown"
+)
+
+// TestStatus is the outcome of a test
+type TestStatus string
+
+const (
+	StatusPassed    TestStatus = "passed"
+	StatusFailed    TestStatus = "failed"
+	StatusSkipped   TestStatus = "skipped"
+	StatusCancelled TestStatus = "cancelled"
+	StatusNotRun    TestStatus = "not_run"
+)
+
 type TestResult struct {
 	Name       string
 	Success    bool
+	Status     TestStatus
 	StatusCode int
 	Duration   time.Duration
 	Response   string
 	Headers    map[string][]string
 	Error      string
-	Variables  map[string]string
+	// Variables holds the extracted variables. Values extracted with json:
+	// keep their JSON type.
+	Variables  map[string]interface{}
+	// Phase is PhaseSetup or PhaseTeardown for setup and teardown tests and
+	// empty for regular tests
+	Phase string
+	// Environment is the name of the environment the suite ran against
+	Environment string
+	// TimedOut is set when the request did not complete within the timeout
+	TimedOut bool
+	// Attempts records every attempt of a test with a retry policy
+	Attempts []Attempt
+	// Iterations holds one result per element of a for_each test. Suite
+	// results list the iterations in place of the test itself.
+	Iterations []*TestResult
+	// SkipReason explains why a skipped or not run test was not executed
+	SkipReason string
+	// FailedDependency names the upstream test whose failure caused this
+	// test to be skipped
+	FailedDependency string
+	// TLSVersion is the negotiated TLS version, e.g. "TLS 1.3", and
+	// CertificateExpiry the expiry of the server certificate. Both are
+	// unset for plain HTTP.
+	TLSVersion        string
+	CertificateExpiry time.Time
+
+	// secrets are the credentials used by the request, masked by reports
+	secrets []string
+}
+
+// Attempt describes a single execution of a test with a retry policy
+type Attempt struct {
+	Number     int
+	Success    bool
+	StatusCode int
+	Duration   time.Duration
+	TimedOut   bool
+	Error      string
+}
+
+// resultStatus returns the status of a result, deriving it from Success for
+// results that were built without one.
+func resultStatus(result *TestResult) TestStatus {
+	if result.Status != "" {
+		return result.Status
+	}
+	if result.Success {
+		return StatusPassed
+	}
+	return StatusFailed
 }
\ No newline at end of file
@@ -0,0 +1,328 @@
+package goresttest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError describes a problem at a position in a suite file
+type ValidationError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	position := e.File
+	if e.Line > 0 {
+		if position != "" {
+			position += ":"
+		}
+		position += fmt.Sprintf("%d", e.Line)
+		if e.Column > 0 {
+			position += fmt.Sprintf(":%d", e.Column)
+		}
+	}
+
+	if position == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", position, e.Message)
+}
+
+// ValidationErrors holds every problem found in a suite and its includes
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "invalid test suite: " + strings.Join(messages, "; ")
+}
+
+// ValidateTestSuiteFile checks a suite file, and the files it includes,
+// without running it. Problems in the YAML are returned as ValidationErrors.
+func ValidateTestSuiteFile(filename string) error {
+	_, err := ParseTestSuite(filename)
+	return err
+}
+
+// schemaNames names the suite types in validation messages
+var schemaNames = map[reflect.Type]string{
+	reflect.TypeOf(TestSuite{}):    "suite",
+	reflect.TypeOf(Test{}):         "test",
+	reflect.TypeOf(TestDefaults{}): "defaults",
+	reflect.TypeOf(ForEach{}):      "for_each",
+	reflect.TypeOf(RetryPolicy{}):  "retry",
+	reflect.TypeOf(Assertion{}):    "assertion",
+	reflect.TypeOf(Environment{}):  "environment",
+	reflect.TypeOf(Auth{}):         "auth",
+	reflect.TypeOf(TLSConfig{}):    "tls",
+}
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// schemaValidator checks the YAML nodes of a suite file against the fields
+// of the types they decode into
+type schemaValidator struct {
+	file     string
+	problems ValidationErrors
+}
+
+// validateSuiteNode returns every problem found in the document node of a
+// suite file
+func validateSuiteNode(root *yaml.Node, filename string) ValidationErrors {
+	v := &schemaValidator{file: filename}
+	if len(root.Content) > 0 {
+		v.validate(root.Content[0], reflect.TypeOf(TestSuite{}))
+	}
+	return v.problems
+}
+
+func (v *schemaValidator) addf(node *yaml.Node, format string, args ...interface{}) {
+	v.problems = append(v.problems, ValidationError{
+		File:    v.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *schemaValidator) validate(node *yaml.Node, t reflect.Type) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		v.validate(node, t.Elem())
+	case reflect.Slice:
+		if node.Kind == yaml.SequenceNode {
+			for _, item := range node.Content {
+				v.validate(item, t.Elem())
+			}
+		}
+	case reflect.Map:
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				v.validate(node.Content[i+1], t.Elem())
+			}
+		}
+	case reflect.Struct:
+		if node.Kind == yaml.MappingNode {
+			v.validateStruct(node, t)
+		}
+	}
+}
+
+func (v *schemaValidator) validateStruct(node *yaml.Node, t reflect.Type) {
+	fields := make(map[string]reflect.Type)
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = t.Field(i).Type
+		names = append(names, name)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			continue
+		}
+
+		fieldType, known := fields[key.Value]
+		if !known {
+			message := fmt.Sprintf("unknown field %q in %s", key.Value, schemaNames[t])
+			suggestion := closestName(key.Value, names)
+			if suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			v.addf(key, "%s", message)
+
+			// Keep checking the misspelled field as the suggested one, so
+			// problems nested in it are reported too.
+			if suggestion != "" {
+				v.validate(value, fields[suggestion])
+			}
+			continue
+		}
+
+		if t == reflect.TypeOf(Test{}) && key.Value == "extract" {
+			v.validateExtractions(value)
+		}
+		v.validate(value, fieldType)
+	}
+
+	if t == reflect.TypeOf(Assertion{}) {
+		v.validateAssertion(node)
+	}
+	if t == reflect.TypeOf(Auth{}) {
+		v.validateAuth(node)
+	}
+}
+
+// validateAuth checks that the auth type is supported by the HTTPClient
+func (v *schemaValidator) validateAuth(node *yaml.Node) {
+	typeNode := mappingValue(node, "type")
+	if typeNode == nil || typeNode.Value == "" {
+		v.addf(node, "auth is missing a type")
+		return
+	}
+	if !hasPlaceholder(typeNode.Value) && !containsString(authTypes, typeNode.Value) {
+		v.addf(typeNode, "unknown auth type %q (supported: %s)", typeNode.Value, strings.Join(authTypes, ", "))
+	}
+}
+
+// validateAssertion checks that the assertion type is supported by the
+// AssertionEngine, and that it supports the operator
+func (v *schemaValidator) validateAssertion(node *yaml.Node) {
+	typeNode, operatorNode := mappingValue(node, "type"), mappingValue(node, "operator")
+	if typeNode == nil || typeNode.Value == "" {
+		v.addf(node, "assertion is missing a type")
+		return
+	}
+	if hasPlaceholder(typeNode.Value) {
+		return
+	}
+
+	operators, known := assertionOperators[typeNode.Value]
+	if !known {
+		v.addf(typeNode, "unknown assertion type %q (supported: %s)", typeNode.Value, strings.Join(sortedKeys(assertionOperators), ", "))
+		return
+	}
+
+	if operatorNode == nil || operatorNode.Value == "" || hasPlaceholder(operatorNode.Value) {
+		return
+	}
+	for _, operator := range operators {
+		if operator == operatorNode.Value {
+			return
+		}
+	}
+	v.addf(operatorNode, "operator %q is not supported by %s assertions (supported: %s)", operatorNode.Value, typeNode.Value, strings.Join(operators, ", "))
+}
+
+// validateExtractions checks that every extraction expression uses an
+// extractor supported by the VariableExtractor
+func (v *schemaValidator) validateExtractions(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		prefix, _, found := strings.Cut(value.Value, ":")
+		if !found {
+			v.addf(value, "invalid extraction expression %q for %q: expected <extractor>:<path>", value.Value, node.Content[i].Value)
+			continue
+		}
+		if hasPlaceholder(prefix) || containsString(extractorTypes, prefix) {
+			continue
+		}
+		v.addf(value, "unknown extractor %q for %q (supported: %s)", prefix, node.Content[i].Value, strings.Join(extractorTypes, ", "))
+	}
+}
+
+// typeErrorLine matches the position prefix of yaml.v3 type errors
+var typeErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// typeErrors converts the errors of a failed decode into ValidationErrors
+func typeErrors(err error, filename string) (ValidationErrors, bool) {
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		return nil, false
+	}
+
+	var problems ValidationErrors
+	for _, message := range typeErr.Errors {
+		problem := ValidationError{File: filename, Message: message}
+		if match := typeErrorLine.FindStringSubmatch(message); match != nil {
+			fmt.Sscanf(match[1], "%d", &problem.Line)
+			problem.Message = match[2]
+		}
+		problems = append(problems, problem)
+	}
+	return problems, true
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hasPlaceholder(s string) bool {
+	return strings.Contains(s, "${")
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// closestName returns the name closest to s, if it is within two edits
+func closestName(s string, names []string) string {
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if distance := editDistance(s, name); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
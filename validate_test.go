@@ -0,0 +1,145 @@
+package goresttest
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTestSuiteFromString_ValidationErrors(t *testing.T) {
+	yamlContent := `name: "Typos"
+tests:
+  - name: "Get User"
+    url: "/users/1"
+    asertions:
+      - type: "status_code"
+        expectd: 200
+    assertions:
+      - type: "json_pth"
+        path: "id"
+      - type: "regex"
+        operator: "equals"
+        expected: "id"
+      - path: "name"
+    extract:
+      id: "jsn:$.id"
+      name: "name"
+    retry:
+      max_attempts: "many"
+`
+
+	_, err := ParseTestSuiteFromString(yamlContent)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expected ValidationErrors, got %T: %v", err, err)
+	}
+
+	expected := []string{
+		`5:5: unknown field "asertions" in test (did you mean "assertions"?)`,
+		`7:9: unknown field "expectd" in assertion (did you mean "expected"?)`,
+		`9:15: unknown assertion type "json_pth"`,
+		`12:19: operator "equals" is not supported by regex assertions (supported: matches, not_matches)`,
+		`14:9: assertion is missing a type`,
+		`16:11: unknown extractor "jsn" for "id"`,
+		`17:13: invalid extraction expression "name" for "name"`,
+		`19: cannot unmarshal !!str ` + "`many`" + ` into int`,
+	}
+
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		if !strings.Contains(problems[i].Error(), want) {
+			t.Errorf("Expected problem %d to contain %q, got %q", i, want, problems[i].Error())
+		}
+	}
+}
+
+func TestParseTestSuite_ValidationErrorsInIncludedFiles(t *testing.T) {
+	dir := writeSuiteFiles(t, map[string]string{
+		"main.yaml":  "include: [\"other.yaml\"]\nparalel: true\n",
+		"other.yaml": "tests:\n  - name: \"A\"\n    url: \"/a\"\n    header:\n      X-Test: \"1\"\n",
+	})
+
+	err := ValidateTestSuiteFile(filepath.Join(dir, "main.yaml"))
+
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expected ValidationErrors, got %T: %v", err, err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %d: %v", len(problems), problems)
+	}
+
+	if want := filepath.Join(dir, "main.yaml") + `:2:1: unknown field "paralel" in suite (did you mean "parallel"?)`; problems[0].Error() != want {
+		t.Errorf("Expected %q, got %q", want, problems[0].Error())
+	}
+	if want := filepath.Join(dir, "other.yaml") + `:4:5: unknown field "header" in test (did you mean "headers"?)`; problems[1].Error() != want {
+		t.Errorf("Expected %q, got %q", want, problems[1].Error())
+	}
+}
+
+func TestParseTestSuiteFromString_ValidSuite(t *testing.T) {
+	yamlContent := `
+name: "Valid"
+defaults: &defaults
+  timeout: 5s
+templates:
+  base:
+    headers:
+      Accept: "application/json"
+tests:
+  - name: "Get ${resource}"
+    extends: "base"
+    url: "/${resource}"
+    matrix:
+      resource: ["posts", "users"]
+    assertions:
+      - type: "${assertion_type}"
+        operator: "${operator}"
+        expected: 200
+      - type: "header"
+        path: "Content-Type"
+        operator: "contains"
+        expected: "json"
+    extract:
+      first: "json:$[0].id"
+      status: "status:"
+`
+
+	if _, err := ParseTestSuiteFromString(yamlContent); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestAssertionOperators_SupportedByEngine(t *testing.T) {
+	engine := NewAssertionEngine()
+	result := &TestResult{StatusCode: 200, Response: `{"id": 1}`, Headers: map[string][]string{"X": {"1"}}}
+
+	for assertionType, operators := range assertionOperators {
+		for _, operator := range operators {
+			assertion := Assertion{Type: assertionType, Path: "X", Operator: operator, Expected: "1"}
+			err := engine.runSingleAssertion(result, assertion, nil)
+			if err != nil && (strings.Contains(err.Error(), "unsupported operator") || strings.Contains(err.Error(), "unknown assertion type")) {
+				t.Errorf("Expected %s %s to be supported, got %v", assertionType, operator, err)
+			}
+		}
+	}
+}
+
+func TestExtractorTypes_SupportedByExtractor(t *testing.T) {
+	extractor := NewVariableExtractor()
+	result := &TestResult{Response: `{"id": 1}`}
+
+	for _, extractorType := range extractorTypes {
+		_, err := extractor.extractValue(result, extractorType+":id")
+		if err != nil && strings.Contains(err.Error(), "unsupported extractor type") {
+			t.Errorf("Expected extractor %s to be supported, got %v", extractorType, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package goresttest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix marks placeholders that read the process environment
+const envPrefix = "env:"
+
+// lookupVariable returns the value of a placeholder name, reading names
+// prefixed with env: from the process environment
+func lookupVariable(name string, variables map[string]interface{}) (interface{}, bool) {
+	if value, exists := variables[name]; exists {
+		return value, true
+	}
+	if strings.HasPrefix(name, envPrefix) {
+		return os.LookupEnv(strings.TrimPrefix(name, envPrefix))
+	}
+	return nil, false
+}
+
+// formatVariable formats a variable value for interpolation into text.
+// Strings are used as they are and every other value is encoded as JSON, so
+// numbers keep their precision and objects and arrays can be passed on.
+func formatVariable(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := marshalJSON(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// marshalJSON encodes value as JSON without escaping HTML characters
+func marshalJSON(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// isEmptyVariable reports whether a variable value counts as unset for a
+// ${name:-default} placeholder
+func isEmptyVariable(value interface{}) bool {
+	s, ok := value.(string)
+	return value == nil || ok && s == ""
+}
+
+// stringVariables converts string values, such as data-driven rows, into
+// variables
+func stringVariables(values map[string]string) map[string]interface{} {
+	variables := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		variables[k] = v
+	}
+	return variables
+}
+
+// mergeVariables returns the variables of base overridden by those of
+// override
+func mergeVariables(base, override map[string]interface{}) map[string]interface{} {
+	if len(base) == 0 {
+		return override
+	}
+
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// OverrideVariables sets variables of the suite, replacing any existing
+// value. The suite's variable map is replaced rather than modified.
+func (s *TestSuite) OverrideVariables(variables map[string]interface{}) {
+	if len(variables) > 0 {
+		s.Variables = mergeVariables(s.Variables, variables)
+	}
+}
+
+// LoadVariablesFile reads a YAML file mapping variable names to values.
+// Values keep their YAML type.
+func LoadVariablesFile(filename string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables file: %w", err)
+	}
+
+	var variables map[string]interface{}
+	if err := yaml.Unmarshal(data, &variables); err != nil {
+		return nil, fmt.Errorf("failed to parse variables file %s: %w", filename, err)
+	}
+	return variables, nil
+}
+
+// LoadEnvFile sets process environment variables from a .env file of
+// KEY=VALUE lines. Variables that are already set are left unchanged, so the
+// real environment always wins over the file. Blank lines, # comments and an
+// export prefix are ignored, and values may be single or double quoted.
+func LoadEnvFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", filename, lineNumber)
+		}
+
+		value, err := parseEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", filename, lineNumber, err)
+		}
+
+		if _, set := os.LookupEnv(key); !set {
+			if err := os.Setenv(key, value); err != nil {
+				return fmt.Errorf("%s:%d: %w", filename, lineNumber, err)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+	return nil
+}
+
+// parseEnvValue unquotes a .env value. Double quoted values support Go
+// escape sequences, single quoted values are taken literally and unquoted
+// values end at an inline comment.
+func parseEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := strings.LastIndex(value, `"`)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		unquoted, err := strconv.Unquote(value[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value: %w", err)
+		}
+		return unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		end := strings.LastIndex(value, "'")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return value[1:end], nil
+	default:
+		if comment := strings.Index(value, " #"); comment >= 0 {
+			value = value[:comment]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
@@ -0,0 +1,337 @@
+package goresttest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// unsetEnv unsets an environment variable for the duration of a test
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func TestInterpolateVariables(t *testing.T) {
+	t.Setenv("GORESTTEST_TOKEN", "secret")
+	unsetEnv(t, "GORESTTEST_MISSING")
+
+	variables := map[string]interface{}{"id": "42", "path": "/users/${id}", "empty": ""}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"/users/${id}", "/users/42"},
+		{"Bearer ${env:GORESTTEST_TOKEN}", "Bearer secret"},
+		{"${undefined} and ${env:GORESTTEST_MISSING}", "${undefined} and ${env:GORESTTEST_MISSING}"},
+		{"${path}", "/users/${id}"},
+		{"${id}${id} ${unterminated", "4242 ${unterminated"},
+		{"/users/${user_id:-1}", "/users/1"},
+		{"${id:-1} ${empty:-fallback} ${undefined:-${id}}", "42 fallback 42"},
+		{"${env:GORESTTEST_MISSING:-none} ${undefined:-}", "none "},
+		{"$${id} costs $5", "${id} costs $5"},
+		{"${undefined:-${missing}}", "${undefined:-${missing}}"},
+	}
+
+	for _, tt := range tests {
+		if got := InterpolateVariables(tt.text, variables); got != tt.expected {
+			t.Errorf("InterpolateVariables(%q): expected %q, got %q", tt.text, tt.expected, got)
+		}
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	for _, key := range []string{"GORESTTEST_A", "GORESTTEST_B", "GORESTTEST_C", "GORESTTEST_D"} {
+		unsetEnv(t, key)
+	}
+	t.Setenv("GORESTTEST_SET", "from environment")
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	content := `# credentials
+GORESTTEST_A=plain value # comment
+export GORESTTEST_B="line\nbreak"
+GORESTTEST_C='single # quoted'
+GORESTTEST_D=
+GORESTTEST_SET=from file
+`
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	if err := LoadEnvFile(filename); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"GORESTTEST_A":   "plain value",
+		"GORESTTEST_B":   "line\nbreak",
+		"GORESTTEST_C":   "single # quoted",
+		"GORESTTEST_D":   "",
+		"GORESTTEST_SET": "from environment",
+	}
+	for key, want := range expected {
+		if got, set := os.LookupEnv(key); !set || got != want {
+			t.Errorf("Expected %s=%q, got %q (set: %t)", key, want, got, set)
+		}
+	}
+
+	if err := os.WriteFile(filename, []byte("NOT A PAIR\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	if err := LoadEnvFile(filename); err == nil {
+		t.Error("Expected error for invalid line, got nil")
+	}
+}
+
+func TestTestRunner_RunTestSuite_VariablePrecedence(t *testing.T) {
+	server, order := recordingServer(t)
+	t.Setenv("GORESTTEST_USER", "from-env")
+
+	filename := filepath.Join(t.TempDir(), "vars.yaml")
+	if err := os.WriteFile(filename, []byte("file: from-file\ncli: from-file\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write variables file: %v", err)
+	}
+	fileVariables, err := LoadVariablesFile(filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	suite := &TestSuite{
+		Variables: map[string]interface{}{
+			"suite": "from-suite",
+			"env":   "from-suite",
+			"file":  "from-suite",
+			"cli":   "from-suite",
+			"user":  "${env:GORESTTEST_USER}",
+		},
+		Environments: map[string]Environment{
+			"staging": {Variables: map[string]interface{}{"env": "from-environment", "file": "from-environment"}},
+		},
+		Tests: []Test{{
+			Name:       "Get",
+			URL:        "/${suite}/${env}/${file}/${cli}/${user}",
+			Assertions: statusOK(),
+		}},
+	}
+
+	runner := NewTestRunner(server.URL)
+	_, err = runner.RunTestSuite(suite,
+		WithEnvironment("staging"),
+		WithVariables(fileVariables),
+		WithVariables(map[string]interface{}{"cli": "from-cli"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "/from-suite/from-environment/from-file/from-cli/from-env"
+	if got := order(); len(got) != 1 || got[0] != want {
+		t.Errorf("Expected request to %s, got %v", want, got)
+	}
+}
+
+func TestHTTPClient_ExecuteRequest_StrictVariables(t *testing.T) {
+	server, order := recordingServer(t)
+
+	test := Test{
+		Name:    "Get User",
+		URL:     "/users/${user_id}",
+		Headers: map[string]string{"Authorization": "Bearer ${token}", "X-Page": "${page:-1}"},
+		Body:    `{"id": "${user_id}", "literal": "$${user_id}"}`,
+	}
+
+	client := NewHTTPClient(server.URL)
+	client.SetStrictVariables(true)
+	result, err := client.ExecuteRequest(test, nil)
+
+	var unresolved *UnresolvedPlaceholdersError
+	if !errors.As(err, &unresolved) {
+		t.Fatalf("Expected UnresolvedPlaceholdersError, got %v", err)
+	}
+	want := []string{"${user_id}", "${token}"}
+	if !reflect.DeepEqual(unresolved.Placeholders, want) {
+		t.Errorf("Expected unresolved placeholders %v, got %v", want, unresolved.Placeholders)
+	}
+	if result.Success || result.Error != "unresolved placeholders: ${user_id}, ${token}" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if got := order(); len(got) != 0 {
+		t.Errorf("Expected no request to be sent, got %v", got)
+	}
+
+	result, err = client.ExecuteRequest(test, map[string]interface{}{"user_id": "7", "token": "abc"})
+	if err != nil || !result.Success {
+		t.Fatalf("Unexpected failure: %v", err)
+	}
+	if got := order(); len(got) != 1 || got[0] != "/users/7" {
+		t.Errorf("Expected request to /users/7, got %v", got)
+	}
+}
+
+func TestTestRunner_RunTestSuite_StrictVariables(t *testing.T) {
+	server, order := recordingServer(t)
+
+	suite := &TestSuite{
+		StrictVariables: true,
+		Tests: []Test{
+			{Name: "Missing", URL: "/users/${user_id}", Retry: &RetryPolicy{MaxAttempts: 3}, Assertions: statusOK()},
+			{Name: "Defaulted", URL: "/users/${user_id:-1}", Assertions: statusOK()},
+		},
+	}
+
+	results, err := NewTestRunner(server.URL).RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != StatusFailed || len(results[0].Attempts) != 1 {
+		t.Errorf("Expected a single failed attempt, got status %s and %d attempts", results[0].Status, len(results[0].Attempts))
+	}
+	if results[1].Status != StatusPassed {
+		t.Errorf("Expected defaulted test to pass, got %s: %s", results[1].Status, results[1].Error)
+	}
+	if got := order(); len(got) != 1 || got[0] != "/users/1" {
+		t.Errorf("Expected only a request to /users/1, got %v", got)
+	}
+}
+
+func TestInterpolateJSON(t *testing.T) {
+	variables := map[string]interface{}{
+		"id":      42,
+		"active":  true,
+		"tags":    []interface{}{"admin", "beta"},
+		"address": map[string]interface{}{"city": "Berlin"},
+		"name":    `O"Brien`,
+		"numeric": "7",
+		"missing": nil,
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{`{"id": ${id}, "active": ${active}}`, `{"id": 42, "active": true}`},
+		{`{"tags": ${tags}, "address": ${address}}`, `{"tags": ["admin","beta"], "address": {"city":"Berlin"}}`},
+		{`{"name": ${name}, "note": "Hi ${name}"}`, `{"name": "O\"Brien", "note": "Hi O\"Brien"}`},
+		{`{"id": ${numeric}, "text": "${id}", "none": ${missing}}`, `{"id": 7, "text": "42", "none": null}`},
+		{`{"escaped \" ${id}": "${tags}"}`, `{"escaped \" 42": "[\"admin\",\"beta\"]"}`},
+		{`{"literal": "$${id}", "undefined": ${undefined}}`, `{"literal": "${id}", "undefined": ${undefined}}`},
+	}
+
+	for _, tt := range tests {
+		if got := InterpolateJSON(tt.text, variables); got != tt.expected {
+			t.Errorf("InterpolateJSON(%q): expected %s, got %s", tt.text, tt.expected, got)
+		}
+	}
+
+	if got := InterpolateVariables("/users/${id}?tags=${tags}", variables); got != `/users/42?tags=["admin","beta"]` {
+		t.Errorf("Unexpected text interpolation: %s", got)
+	}
+}
+
+func TestTestRunner_RunTestSuite_TypedVariables(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 12345678901234567, "admin": true, "roles": ["admin", "beta"], "address": {"city": "Berlin"}}`))
+	}))
+	defer server.Close()
+
+	suite, err := ParseTestSuiteFromString(`
+name: "Typed"
+variables:
+  limit: 10
+tests:
+  - name: "Get"
+    url: "/user"
+    extract:
+      id: "json:$.id"
+      admin: "json:$.admin"
+      roles: "json:$.roles"
+      address: "json:$.address"
+  - name: "Update"
+    method: "PUT"
+    url: "/user/${id}"
+    depends_on: ["Get"]
+    headers:
+      Content-Type: "application/json"
+    body: '{"id": ${id}, "admin": ${admin}, "roles": ${roles}, "address": ${address}, "limit": ${limit}}'
+    run_if: "${id} == 12345678901234567"
+    assertions:
+      - type: "json_path"
+        path: "id"
+        expected: "${id}"
+      - type: "json_path"
+        path: "roles"
+        expected: "${roles}"
+      - type: "json_path"
+        path: "address"
+        expected: "${address}"
+      - type: "json_path"
+        path: "admin"
+        expected: "${admin}"
+      - type: "json_path"
+        path: "roles"
+        expected: "beta"
+        operator: "contains"
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results, err := NewTestRunner(server.URL).RunTestSuite(suite)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != StatusPassed {
+			t.Errorf("Expected %s to pass, got %s: %s", result.Name, result.Status, result.Error)
+		}
+	}
+
+	if _, ok := results[0].Variables["roles"].([]interface{}); !ok {
+		t.Errorf("Expected roles to be extracted as a list, got %T", results[0].Variables["roles"])
+	}
+
+	want := `{"id": 12345678901234567, "admin": true, "roles": ["admin","beta"], "address": {"city":"Berlin"}, "limit": 10}`
+	if len(received) != 2 || received[1] != want {
+		t.Errorf("Expected body %s, got %v", want, received)
+	}
+}
+
+func TestReporter_GenerateHTMLReport_TypedVariables(t *testing.T) {
+	results := []*TestResult{{
+		Name:    "Get",
+		Success: true,
+		Variables: map[string]interface{}{
+			"address": map[string]interface{}{"city": "Berlin"},
+			"roles":   []interface{}{"admin", "beta"},
+		},
+	}}
+
+	filename := filepath.Join(t.TempDir(), "report.html")
+	if err := NewReporter().GenerateHTMLReport(results, filename); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	report, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	// Variables are shown as JSON, as in the console report
+	for _, want := range []string{`address: {&#34;city&#34;:&#34;Berlin&#34;}`, `roles: [&#34;admin&#34;,&#34;beta&#34;]`} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("Expected %s in the HTML report", want)
+		}
+	}
+}